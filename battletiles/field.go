@@ -0,0 +1,191 @@
+package battletiles
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+
+	"github.com/murkland/bnrom/gifanim"
+)
+
+const (
+	FieldCols = 6
+	FieldRows = 3
+
+	// edgeHeight is how much of the edge row below the bottom panels is drawn.
+	edgeHeight = 8
+
+	FieldWidth  = FieldCols * Width
+	FieldHeight = FieldRows*Height + edgeHeight
+)
+
+// Panel is the state of one panel on the field.
+type Panel struct {
+	Type PanelType
+
+	Blue bool
+
+	// Frame is which frame of the panel's palette animation to draw, starting from its first palbank.
+	Frame int
+}
+
+type Field [FieldRows][FieldCols]Panel
+
+// DefaultField is a field of normal panels with the player's three columns on the left in red.
+var DefaultField = func() Field {
+	var f Field
+	for j := range f {
+		for i := range f[j] {
+			f[j][i] = Panel{Type: PanelTypeNormal, Blue: i >= FieldCols/2}
+		}
+	}
+	return f
+}()
+
+// Renderer draws fields with a game's panel tiles and palettes.
+type Renderer struct {
+	layout   *Layout
+	tiles    Tiles
+	palbanks []color.Palette
+}
+
+func NewRenderer(r io.ReadSeeker, ri ROMInfo) (*Renderer, error) {
+	palbanks, err := ReadPalbanks(r, ri)
+	if err != nil {
+		return nil, fmt.Errorf("%w while reading palbanks", err)
+	}
+
+	tiles, err := ReadTiles(r, ri)
+	if err != nil {
+		return nil, fmt.Errorf("%w while reading tiles", err)
+	}
+
+	return &Renderer{ri.Layout, tiles, palbanks}, nil
+}
+
+func (rd *Renderer) palbanksFor(p Panel) ([]int, error) {
+	if p.Type == PanelTypeEdge || !rd.layout.Has(p.Type) {
+		return nil, fmt.Errorf("battletiles: %s is not a panel type of this game", p.Type)
+	}
+	return rd.layout.Palbanks(p.Type, p.Blue), nil
+}
+
+func (rd *Renderer) draw(dst *image.RGBA, r image.Rectangle, tile *image.Paletted, palette color.Palette) {
+	for y := 0; y < r.Dy(); y++ {
+		for x := 0; x < r.Dx(); x++ {
+			pix := tile.Pix[y*tile.Stride+x]
+			if pix == 0 {
+				continue
+			}
+			dst.Set(r.Min.X+x, r.Min.Y+y, palette[pix])
+		}
+	}
+}
+
+// Render draws a field the way the game lays it out: three rows of six panels with the edge row under the bottom
+// panels, each edge tile colored after the panel above it.
+func (rd *Renderer) Render(f Field) (*image.RGBA, error) {
+	img := image.NewRGBA(image.Rect(0, 0, FieldWidth, FieldHeight))
+
+	for j, row := range f {
+		for i, p := range row {
+			palbanks, err := rd.palbanksFor(p)
+			if err != nil {
+				return nil, err
+			}
+
+			palbank := palbanks[((p.Frame%len(palbanks))+len(palbanks))%len(palbanks)]
+			rd.draw(img, image.Rect(i*Width, j*Height, (i+1)*Width, (j+1)*Height), rd.tiles.Panel(p.Type, j), rd.palbanks[palbank])
+		}
+	}
+
+	for i, p := range f[FieldRows-1] {
+		y := FieldRows * Height
+		rd.draw(img, image.Rect(i*Width, y, (i+1)*Width, y+edgeHeight), rd.tiles.Panel(PanelTypeEdge, 0), rd.palbanks[rd.layout.Palbanks(PanelTypeEdge, p.Blue)[0]])
+	}
+
+	return img, nil
+}
+
+// RenderAnimation renders one full loop of every panel's palette animation on a field. A frame is emitted whenever any
+// panel changes, with its own exact palette.
+func (rd *Renderer) RenderAnimation(f Field) (*gifanim.Animation, error) {
+	loop := 1
+	for _, row := range f {
+		for _, p := range row {
+			palbanks, err := rd.palbanksFor(p)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(palbanks) == 1 {
+				continue
+			}
+
+			period := len(palbanks) * rd.layout.FrameTime(p.Type)
+			loop = gifanim.LCM(loop, period)
+		}
+	}
+
+	return gifanim.Record(loop, func(t int) (*image.Paletted, int, error) {
+		next := loop
+		var frame Field
+		for j, row := range f {
+			for i, p := range row {
+				palbanks, _ := rd.palbanksFor(p)
+				frameTime := rd.layout.FrameTime(p.Type)
+
+				p.Frame += t / frameTime
+				frame[j][i] = p
+
+				if len(palbanks) > 1 {
+					if change := (t/frameTime + 1) * frameTime; change < next {
+						next = change
+					}
+				}
+			}
+		}
+
+		img, err := rd.Render(frame)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		pimg, err := toPaletted(img)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w while converting frame at %d", err, t)
+		}
+
+		return pimg, next, nil
+	})
+}
+
+var ErrTooManyColors = errors.New("battletiles: frame has more than 256 colors")
+
+// toPaletted converts a frame to an image with an exact palette, with index 0 transparent.
+func toPaletted(img *image.RGBA) (*image.Paletted, error) {
+	palette := color.Palette{color.RGBA{}}
+	indexes := map[color.RGBA]uint8{{}: 0}
+
+	pimg := image.NewPaletted(img.Rect, nil)
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			idx, ok := indexes[c]
+			if !ok {
+				if len(palette) == 256 {
+					return nil, ErrTooManyColors
+				}
+				idx = uint8(len(palette))
+				indexes[c] = idx
+				palette = append(palette, c)
+			}
+			pimg.SetColorIndex(x, y, idx)
+		}
+	}
+	pimg.Palette = palette
+
+	return pimg, nil
+}
@@ -0,0 +1,133 @@
+package battletiles
+
+// panelLayout is where a panel type's tiles and palbanks are.
+type panelLayout struct {
+	// tilings holds the tiling of each row of the field the panel type has tiles for.
+	tilings [][]int
+
+	// red and blue are the palbanks of each side, one per frame of the panel's palette animation.
+	red  []int
+	blue []int
+
+	// frameTime is how long each frame of the palette animation lasts, in 1/60ths of a second.
+	frameTime int
+}
+
+// Layout describes how a game arranges its panel tiles and palbanks.
+type Layout struct {
+	// PanelTypes lists the game's panel types in the order their tiles are stored.
+	PanelTypes []PanelType
+
+	panels map[PanelType]panelLayout
+}
+
+// Has reports whether the game has tiles for a panel type.
+func (l *Layout) Has(t PanelType) bool {
+	_, ok := l.panels[t]
+	return ok
+}
+
+// Palbanks returns the palbanks a panel is drawn with, one per frame of its palette animation.
+func (l *Layout) Palbanks(t PanelType, blue bool) []int {
+	if blue {
+		return l.panels[t].blue
+	}
+	return l.panels[t].red
+}
+
+// FrameTime returns how long each frame of a panel's palette animation lasts, in 1/60ths of a second.
+func (l *Layout) FrameTime(t PanelType) int {
+	return l.panels[t].frameTime
+}
+
+func (l *Layout) maxPalbank() int {
+	max := -1
+	for _, p := range l.panels {
+		for _, palbank := range append(append([]int(nil), p.red...), p.blue...) {
+			if palbank > max {
+				max = palbank
+			}
+		}
+	}
+	return max
+}
+
+// PalbankAnimation is the palette animation of a panel type on one side of the field.
+type PalbankAnimation struct {
+	Type PanelType
+	Blue bool
+
+	// Palbanks holds the palbank of each frame.
+	Palbanks []int
+
+	// FrameTime is how long each frame lasts, in 1/60ths of a second.
+	FrameTime int
+}
+
+// PalbankAnimations lists the palette animations of every panel type that has more than one frame, red side first,
+// in layout order.
+func (l *Layout) PalbankAnimations() []PalbankAnimation {
+	var anims []PalbankAnimation
+	for _, blue := range []bool{false, true} {
+		for _, t := range l.PanelTypes {
+			palbanks := l.Palbanks(t, blue)
+			if len(palbanks) < 2 {
+				continue
+			}
+			anims = append(anims, PalbankAnimation{t, blue, palbanks, l.FrameTime(t)})
+		}
+	}
+	return anims
+}
+
+func rowTilings(tiling []int, offsets ...int) [][]int {
+	tilings := make([][]int, len(offsets))
+	for i, offset := range offsets {
+		tilings[i] = offsetTiling(tiling, offset)
+	}
+	return tilings
+}
+
+const (
+	poisonFrameTime = 16
+	holyFrameTime   = 10
+	roadFrameTime   = 8
+)
+
+var bn6Layout = &Layout{
+	PanelTypes: []PanelType{
+		PanelTypeHole,
+		PanelTypeBroken,
+		PanelTypeNormal,
+		PanelTypeCracked,
+		PanelTypePoison,
+		PanelTypeHoly,
+		PanelTypeGrass,
+		PanelTypeIce,
+		PanelTypeVolcano,
+		PanelTypeRoadUp,
+		PanelTypeRoadDown,
+		PanelTypeRoadLeft,
+		PanelTypeRoadRight,
+		PanelTypeEdge,
+	},
+	panels: map[PanelType]panelLayout{
+		PanelTypeHole: {rowTilings(tilingSymmetrical, 0, 8, 16), []int{35}, []int{39}, 1},
+		PanelTypeBroken: {
+			[][]int{offsetTiling(tilingBroken0, 24), offsetTiling(tilingAsymmetrical, 38), offsetTiling(tilingAsymmetrical, 53)},
+			[]int{35}, []int{39}, 1,
+		},
+		PanelTypeNormal:    {rowTilings(tilingSymmetrical, 68, 76, 84), []int{35}, []int{39}, 1},
+		PanelTypeCracked:   {rowTilings(tilingAsymmetrical, 92, 107, 122), []int{35}, []int{39}, 1},
+		PanelTypePoison:    {rowTilings(tilingAsymmetrical, 137, 152, 167), []int{36, 2, 3, 4, 5, 6}, []int{40, 18, 19, 20, 21, 22}, poisonFrameTime},
+		PanelTypeHoly:      {rowTilings(tilingSymmetricalB, 182, 191, 200), []int{38, 10, 11, 12, 13, 14, 15}, []int{42, 26, 27, 28, 29, 30, 31}, holyFrameTime},
+		PanelTypeGrass:     {rowTilings(tilingAsymmetrical, 209, 224, 239), []int{35}, []int{39}, 1},
+		PanelTypeIce:       {rowTilings(tilingAsymmetrical, 254, 269, 284), []int{37}, []int{41}, 1},
+		PanelTypeVolcano:   {rowTilings(tilingAsymmetrical, 299, 314, 329), []int{36}, []int{40}, 1},
+		PanelTypeRoadUp:    {rowTilings(tilingSymmetricalB, 344, 353, 362), []int{37, 7, 8, 9}, []int{41, 23, 24, 25}, roadFrameTime},
+		PanelTypeRoadDown:  {rowTilings(tilingSymmetricalB, 371, 380, 389), []int{37, 7, 8, 9}, []int{41, 23, 24, 25}, roadFrameTime},
+		PanelTypeRoadLeft:  {rowTilings(flipTiling(tilingAsymmetrical), 398, 413, 428), []int{37, 7, 8, 9}, []int{41, 23, 24, 25}, roadFrameTime},
+		PanelTypeRoadRight: {rowTilings(tilingAsymmetrical, 443, 458, 473), []int{37, 7, 8, 9}, []int{41, 23, 24, 25}, roadFrameTime},
+		PanelTypeEdge:      {[][]int{{491, 492, 493, -492, -491}}, []int{35}, []int{39}, 1},
+	},
+}
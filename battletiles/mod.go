@@ -3,14 +3,21 @@ package battletiles
 type ROMInfo struct {
 	TilesOffset int64
 	PalOffset   int64
+
+	// NumPalbanks is how many palbanks the palette table holds. If 0, it is taken to end at the last palbank Layout
+	// refers to. ReadPalbanks checks it against both Layout and the end of the ROM.
+	NumPalbanks int
+
+	Layout *Layout
 }
 
+// FindROMInfo returns where a game keeps its panel tiles, or nil if they haven't been located for it.
 func FindROMInfo(romID string) *ROMInfo {
 	switch romID {
 	case "BR6E", "BR6P", "BR5E", "BR5P":
-		return &ROMInfo{0x0000761C, 0x0000C16C}
+		return &ROMInfo{0x0000761C, 0x0000C16C, 45, bn6Layout}
 	case "BR6J", "BR5J":
-		return &ROMInfo{0x00007610, 0x0000C788}
+		return &ROMInfo{0x00007610, 0x0000C788, 45, bn6Layout}
 	}
 	return nil
 }
@@ -3,53 +3,48 @@ package battletiles
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
 	"io"
-	"log"
 	"os"
 
 	"github.com/murkland/gbarom/bgr555"
 )
 
-const paletteOffsetPtr = 0x0000C16C
-
-var RedTileByIndex = [][]int{
-	{35},
-	{35},
-	{35},
-	{35},
-	{36, 2, 3, 4, 5, 6},
-	{38, 10, 11, 12, 13, 14, 15},
-	{35},
-	{37},
-	{36},
-	{37, 7, 8, 9},
-	{37, 7, 8, 9},
-	{37, 7, 8, 9},
-	{37, 7, 8, 9},
-	{35},
-}
+const palbankSize = 16 * 2
+
+var ErrBadPalbankCount = errors.New("battletiles: bad palbank count")
+
+// numPalbanks is how many palbanks to read: ri.NumPalbanks if set, otherwise enough to cover every palbank the layout
+// refers to. It is an error for the palette table to hold fewer palbanks than the layout refers to.
+func numPalbanks(ri ROMInfo) (int, error) {
+	needed := ri.Layout.maxPalbank() + 1
+	if ri.NumPalbanks == 0 {
+		return needed, nil
+	}
 
-var BlueTileByIndex = [][]int{
-	{39},
-	{39},
-	{39},
-	{39},
-	{40, 18, 19, 20, 21, 22},
-	{42, 26, 27, 28, 29, 30, 31},
-	{39},
-	{41},
-	{40},
-	{41, 23, 24, 25},
-	{41, 23, 24, 25},
-	{41, 23, 24, 25},
-	{41, 23, 24, 25},
-	{39},
+	if ri.NumPalbanks < needed {
+		return 0, fmt.Errorf("%w: layout refers to %d palbanks but the palette table holds %d",
+			ErrBadPalbankCount, needed, ri.NumPalbanks)
+	}
+	return ri.NumPalbanks, nil
 }
 
+// ReadPalbanks reads the palette table. The table must fit in the ROM, so a count that runs past its end is an error
+// rather than a short read.
 func ReadPalbanks(r io.ReadSeeker, ri ROMInfo) ([]color.Palette, error) {
+	n, err := numPalbanks(ri)
+	if err != nil {
+		return nil, err
+	}
+
+	romSize, err := r.Seek(0, os.SEEK_END)
+	if err != nil {
+		return nil, fmt.Errorf("%w while seeking to end of ROM", err)
+	}
+
 	if _, err := r.Seek(ri.PalOffset, os.SEEK_SET); err != nil {
 		return nil, fmt.Errorf("%w while seeking to palette offset pointer", err)
 	}
@@ -59,15 +54,21 @@ func ReadPalbanks(r io.ReadSeeker, ri ROMInfo) ([]color.Palette, error) {
 		return nil, fmt.Errorf("%w while reading to palette offset pointer", err)
 	}
 
-	if _, err := r.Seek(int64(palettePtr & ^uint32(0x08000000)), os.SEEK_SET); err != nil {
+	paletteOffset := int64(palettePtr & ^uint32(0x08000000))
+	if max := (romSize - paletteOffset) / palbankSize; paletteOffset > romSize || int64(n) > max {
+		return nil, fmt.Errorf("%w: palette table at 0x%08x can't hold %d palbanks before the end of the ROM",
+			ErrBadPalbankCount, paletteOffset, n)
+	}
+
+	if _, err := r.Seek(paletteOffset, os.SEEK_SET); err != nil {
 		return nil, fmt.Errorf("%w while seeking to palette offset", err)
 	}
 
 	var palbanks []color.Palette
-	for i := 0; i < 45; i++ {
-		var raw [16 * 2]byte
+	for i := 0; i < n; i++ {
+		var raw [palbankSize]byte
 		if _, err := io.ReadFull(r, raw[:]); err != nil {
-			log.Fatalf("%s", err)
+			return nil, fmt.Errorf("%w while reading palbank %d", err, i)
 		}
 
 		var palette color.Palette
@@ -75,7 +76,7 @@ func ReadPalbanks(r io.ReadSeeker, ri ROMInfo) ([]color.Palette, error) {
 		for j := 0; j < 16; j++ {
 			var c uint16
 			if err := binary.Read(palR, binary.LittleEndian, &c); err != nil {
-				log.Fatalf("%s", err)
+				return nil, fmt.Errorf("%w while reading palbank %d", err, i)
 			}
 
 			palette = append(palette, bgr555.ToRGBA(c))
@@ -88,12 +89,14 @@ func ReadPalbanks(r io.ReadSeeker, ri ROMInfo) ([]color.Palette, error) {
 	return palbanks, nil
 }
 
-func ConsolidatePalbank(palbanks []color.Palette, tilePaletteses [][]int) (color.Palette, map[int]int) {
+// ConsolidatePalbank merges the palbanks used by every panel type of one side into a single palette, returning it and
+// where each palbank's colors start in it.
+func (l *Layout) ConsolidatePalbank(palbanks []color.Palette, blue bool) (color.Palette, map[int]int) {
 	var consolidated color.Palette
 	m := map[int]int{}
-	consolidated = append(consolidated, palbanks[tilePaletteses[0][0]][:7]...)
-	for _, tilePalettes := range tilePaletteses {
-		for _, paletteIdx := range tilePalettes {
+	consolidated = append(consolidated, palbanks[l.Palbanks(l.PanelTypes[0], blue)[0]][:7]...)
+	for _, t := range l.PanelTypes {
+		for _, paletteIdx := range l.Palbanks(t, blue) {
 			if _, ok := m[paletteIdx]; !ok {
 				m[paletteIdx] = len(consolidated)
 				consolidated = append(consolidated, palbanks[paletteIdx][7:]...)
@@ -0,0 +1,57 @@
+package battletiles
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/murkland/gbarom/bgr555"
+)
+
+// fakePaletteROM returns a ROM with a pointer at 0 to a palette table of n palbanks at 4, where every color of palbank i
+// is i.
+func fakePaletteROM(n int) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(0x08000004))
+	for i := 0; i < n; i++ {
+		for j := 0; j < 16; j++ {
+			binary.Write(&buf, binary.LittleEndian, uint16(i))
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestReadPalbanks(t *testing.T) {
+	needed := bn6Layout.maxPalbank() + 1
+
+	for _, tc := range []struct {
+		name        string
+		tableSize   int
+		numPalbanks int
+		want        int
+		wantErr     error
+	}{
+		{"from layout", needed, 0, needed, nil},
+		{"from ROMInfo", needed + 2, needed + 2, needed + 2, nil},
+		{"fewer than layout", needed, needed - 1, 0, ErrBadPalbankCount},
+		{"past end of ROM", needed, needed + 1, 0, ErrBadPalbankCount},
+		{"layout past end of ROM", needed - 1, 0, 0, ErrBadPalbankCount},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := bytes.NewReader(fakePaletteROM(tc.tableSize))
+			palbanks, err := ReadPalbanks(r, ROMInfo{PalOffset: 0, NumPalbanks: tc.numPalbanks, Layout: bn6Layout})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("ReadPalbanks() error = %v, want %v", err, tc.wantErr)
+			}
+			if len(palbanks) != tc.want {
+				t.Fatalf("ReadPalbanks() read %d palbanks, want %d", len(palbanks), tc.want)
+			}
+			for i, palbank := range palbanks {
+				if got, want := palbank[1], bgr555.ToRGBA(uint16(i)); got != want {
+					t.Errorf("palbank %d color 1 = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
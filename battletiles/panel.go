@@ -0,0 +1,95 @@
+package battletiles
+
+import (
+	"image"
+)
+
+// PanelType is a kind of panel. Which panel types a game has, and where their tiles are, is given by its Layout.
+type PanelType int
+
+const (
+	PanelTypeNormal PanelType = iota
+	PanelTypeCracked
+	PanelTypeBroken
+	PanelTypeHole
+	PanelTypePoison
+	PanelTypeHoly
+	PanelTypeGrass
+	PanelTypeIce
+	PanelTypeVolcano
+	PanelTypeRoadUp
+	PanelTypeRoadDown
+	PanelTypeRoadLeft
+	PanelTypeRoadRight
+
+	// PanelTypeEdge is the row drawn under the bottom panels.
+	PanelTypeEdge
+)
+
+// PanelTypes lists every panel type.
+var PanelTypes = []PanelType{
+	PanelTypeNormal,
+	PanelTypeCracked,
+	PanelTypeBroken,
+	PanelTypeHole,
+	PanelTypePoison,
+	PanelTypeHoly,
+	PanelTypeGrass,
+	PanelTypeIce,
+	PanelTypeVolcano,
+	PanelTypeRoadUp,
+	PanelTypeRoadDown,
+	PanelTypeRoadLeft,
+	PanelTypeRoadRight,
+	PanelTypeEdge,
+}
+
+var panelTypeNames = []string{
+	"normal",
+	"cracked",
+	"broken",
+	"hole",
+	"poison",
+	"holy",
+	"grass",
+	"ice",
+	"volcano",
+	"roadup",
+	"roaddown",
+	"roadleft",
+	"roadright",
+	"edge",
+}
+
+func (t PanelType) valid() bool {
+	return t >= 0 && int(t) < len(panelTypeNames)
+}
+
+func (t PanelType) String() string {
+	if !t.valid() {
+		return "unknown"
+	}
+	return panelTypeNames[t]
+}
+
+// ParsePanelType looks a panel type up by the name String gives it.
+func ParsePanelType(name string) (PanelType, bool) {
+	for i, n := range panelTypeNames {
+		if n == name {
+			return PanelType(i), true
+		}
+	}
+	return 0, false
+}
+
+// Tiles are the images of a game's panel types, one per row of the field, as read by ReadTiles.
+type Tiles map[PanelType][]*image.Paletted
+
+// Panel returns the image of a panel type for a row of the field, or nil if the game has no such panel.
+func (ts Tiles) Panel(t PanelType, row int) *image.Paletted {
+	imgs := ts[t]
+	if row < 0 || row >= len(imgs) {
+		return nil
+	}
+	return imgs[row]
+}
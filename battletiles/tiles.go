@@ -13,8 +13,6 @@ import (
 	"github.com/murkland/gbarom/lz77"
 )
 
-const tilesOffsetPtr = 0x0000761C
-
 var tilingSymmetrical = []int{
 	1, 2, 3, -2, -1,
 	4, 5, 5, 5, -4,
@@ -56,67 +54,45 @@ func flipTiling(tiling []int) []int {
 	return t
 }
 
-var tileGroups = [][]int{
-	// hole
-	tilingSymmetrical,
-	offsetTiling(tilingSymmetrical, 8),
-	offsetTiling(tilingSymmetrical, 16),
-	// broken
-	offsetTiling(tilingBroken0, 24),
-	offsetTiling(tilingAsymmetrical, 38),
-	offsetTiling(tilingAsymmetrical, 53),
-	// normal
-	offsetTiling(tilingSymmetrical, 68),
-	offsetTiling(tilingSymmetrical, 76),
-	offsetTiling(tilingSymmetrical, 84),
-	// cracked
-	offsetTiling(tilingAsymmetrical, 92),
-	offsetTiling(tilingAsymmetrical, 107),
-	offsetTiling(tilingAsymmetrical, 122),
-	// poison
-	offsetTiling(tilingAsymmetrical, 137),
-	offsetTiling(tilingAsymmetrical, 152),
-	offsetTiling(tilingAsymmetrical, 167),
-	// holy
-	offsetTiling(tilingSymmetricalB, 182),
-	offsetTiling(tilingSymmetricalB, 191),
-	offsetTiling(tilingSymmetricalB, 200),
-	// grass
-	offsetTiling(tilingAsymmetrical, 209),
-	offsetTiling(tilingAsymmetrical, 224),
-	offsetTiling(tilingAsymmetrical, 239),
-	// ice
-	offsetTiling(tilingAsymmetrical, 254),
-	offsetTiling(tilingAsymmetrical, 269),
-	offsetTiling(tilingAsymmetrical, 284),
-	// volcano
-	offsetTiling(tilingAsymmetrical, 299),
-	offsetTiling(tilingAsymmetrical, 314),
-	offsetTiling(tilingAsymmetrical, 329),
-	// road up
-	offsetTiling(tilingSymmetricalB, 344),
-	offsetTiling(tilingSymmetricalB, 353),
-	offsetTiling(tilingSymmetricalB, 362),
-	// road down
-	offsetTiling(tilingSymmetricalB, 371),
-	offsetTiling(tilingSymmetricalB, 380),
-	offsetTiling(tilingSymmetricalB, 389),
-	// road left
-	offsetTiling(flipTiling(tilingAsymmetrical), 398),
-	offsetTiling(flipTiling(tilingAsymmetrical), 413),
-	offsetTiling(flipTiling(tilingAsymmetrical), 428),
-	// road right
-	offsetTiling(tilingAsymmetrical, 443),
-	offsetTiling(tilingAsymmetrical, 458),
-	offsetTiling(tilingAsymmetrical, 473),
-	// edge
-	{491, 492, 493, -492, -491},
-}
-
 const Width = 5 * 8
 const Height = 3 * 8
 
-func ReadTiles(r io.ReadSeeker, ri ROMInfo) ([]*image.Paletted, error) {
+// readPanel draws a panel from its tiling: 1-based indexes into rawTiles, negative for tiles flipped horizontally.
+func readPanel(rawTiles []byte, tiling []int) (*image.Paletted, error) {
+	img := image.NewPaletted(image.Rect(0, 0, Width, Height), nil)
+
+	for i, tIndex := range tiling {
+		flipH := false
+		if tIndex < 0 {
+			flipH = true
+			tIndex = -tIndex
+		}
+		tIndex--
+
+		if (tIndex+1)*8*8/2 > len(rawTiles) {
+			return nil, fmt.Errorf("tile %d is past the end of the tiles", tIndex)
+		}
+
+		tileImg, err := sprites.ReadTile(bytes.NewBuffer(rawTiles[tIndex*8*8/2:(tIndex+1)*8*8/2]), image.Rect(0, 0, 8, 8))
+		if err != nil {
+			return nil, fmt.Errorf("%w while reading tile %d", err, tIndex)
+		}
+
+		if flipH {
+			paletted.FlipHorizontal(tileImg)
+		}
+
+		x := (i % 5) * 8
+		y := (i / 5) * 8
+
+		paletted.DrawOver(img, image.Rect(x, y, x+8, y+8), tileImg, image.Point{})
+	}
+
+	return img, nil
+}
+
+// ReadTiles reads the image of every panel type in the game's layout.
+func ReadTiles(r io.ReadSeeker, ri ROMInfo) (Tiles, error) {
 	if _, err := r.Seek(ri.TilesOffset, os.SEEK_SET); err != nil {
 		return nil, fmt.Errorf("%w while seeking to tile offset pointer", err)
 	}
@@ -135,78 +111,44 @@ func ReadTiles(r io.ReadSeeker, ri ROMInfo) ([]*image.Paletted, error) {
 		return nil, fmt.Errorf("%w while decompressing tiles", err)
 	}
 
-	tiles := make([]*image.Paletted, len(tileGroups))
-	for j, tg := range tileGroups {
-		tiles[j] = image.NewPaletted(image.Rect(0, 0, Width, Height), nil)
-
-		for i, tIndex := range tg {
-			flipH := false
-			if tIndex < 0 {
-				flipH = true
-				tIndex = -tIndex
-			}
-			tIndex--
-
-			tileImg, err := sprites.ReadTile(bytes.NewBuffer(rawTiles[tIndex*8*8/2:(tIndex+1)*8*8/2]), image.Rect(0, 0, 8, 8))
+	tiles := Tiles{}
+	for _, t := range ri.Layout.PanelTypes {
+		for _, tiling := range ri.Layout.panels[t].tilings {
+			img, err := readPanel(rawTiles, tiling)
 			if err != nil {
-				return nil, fmt.Errorf("%w while reading tile %d", err, tIndex)
+				return nil, fmt.Errorf("%w while reading %s panel", err, t)
 			}
-
-			if flipH {
-				paletted.FlipHorizontal(tileImg)
-			}
-
-			x := (i % 5) * 8
-			y := (i / 5) * 8
-
-			paletted.DrawOver(tiles[j], image.Rect(x, y, x+8, y+8), tileImg, image.Point{})
+			tiles[t] = append(tiles[t], img)
 		}
 	}
 
 	return tiles, nil
 }
 
-const (
-	poisonFrameTime = 16
-	holyFrameTime   = 10
-	roadFrameTime   = 8
-)
-
-var frameTimes = []int{
-	1,
-	1,
-	1,
-	1,
-	poisonFrameTime,
-	holyFrameTime,
-	1,
-	1,
-	1,
-	roadFrameTime,
-	roadFrameTime,
-	roadFrameTime,
-	roadFrameTime,
-	1,
-}
-
 type FrameInfo struct {
 	Delay int
 	IsEnd bool
 }
 
-var FrameInfos = func() []FrameInfo {
-	frameInfos := make([]FrameInfo, 0, len(RedTileByIndex)*3)
-	for k, tiles := range RedTileByIndex[:len(RedTileByIndex)-1] {
-		for j := 0; j < 3; j++ {
-			for i := 0; i < len(tiles); i++ {
-				fi := FrameInfo{
-					Delay: frameTimes[k],
-					IsEnd: i == len(tiles)-1,
-				}
-				frameInfos = append(frameInfos, fi)
+// FrameInfos returns the frames of the red side's panel animations, one per palbank of each row of every panel type in
+// layout order. Only the first frame of the edge is included.
+func (l *Layout) FrameInfos() []FrameInfo {
+	var frameInfos []FrameInfo
+	for _, t := range l.PanelTypes {
+		palbanks := l.Palbanks(t, false)
+		if t == PanelTypeEdge {
+			frameInfos = append(frameInfos, FrameInfo{1, true})
+			continue
+		}
+
+		for range l.panels[t].tilings {
+			for i := range palbanks {
+				frameInfos = append(frameInfos, FrameInfo{
+					Delay: l.FrameTime(t),
+					IsEnd: i == len(palbanks)-1,
+				})
 			}
 		}
 	}
-	frameInfos = append(frameInfos, FrameInfo{1, true})
 	return frameInfos
-}()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/murkland/bnrom/battletiles"
+	"github.com/murkland/bnrom/gifanim"
+	"github.com/murkland/gbarom"
+)
+
+// parseField parses a field given as rows separated by slashes, each of six comma-separated panels written as r or b
+// for the owner, a colon and the panel type, e.g. r:normal,r:normal,r:poison,b:normal,b:normal,b:normal/...
+func parseField(s string) (battletiles.Field, error) {
+	var f battletiles.Field
+
+	rows := strings.Split(s, "/")
+	if len(rows) != battletiles.FieldRows {
+		return f, fmt.Errorf("field must have %d rows, got %d", battletiles.FieldRows, len(rows))
+	}
+
+	for j, row := range rows {
+		panels := strings.Split(row, ",")
+		if len(panels) != battletiles.FieldCols {
+			return f, fmt.Errorf("field row %d must have %d panels, got %d", j, battletiles.FieldCols, len(panels))
+		}
+
+		for i, panel := range panels {
+			owner, name, ok := strings.Cut(strings.TrimSpace(panel), ":")
+			if !ok || (owner != "r" && owner != "b") {
+				return f, fmt.Errorf("bad panel %q at (%d, %d)", panel, i, j)
+			}
+
+			typ, ok := battletiles.ParsePanelType(name)
+			if !ok || typ == battletiles.PanelTypeEdge {
+				return f, fmt.Errorf("bad panel type %q at (%d, %d)", name, i, j)
+			}
+
+			f[j][i] = battletiles.Panel{Type: typ, Blue: owner == "b"}
+		}
+	}
+
+	return f, nil
+}
+
+// dumpBattlefield renders a field as outFn.png and its panel animations as outFn.gif. An empty spec renders
+// battletiles.DefaultField.
+func dumpBattlefield(r io.ReadSeeker, spec string, outFn string) error {
+	romID, err := gbarom.ReadROMID(r)
+	if err != nil {
+		return err
+	}
+
+	info := battletiles.FindROMInfo(romID)
+	if info == nil {
+		return errors.New("unsupported game")
+	}
+
+	field := battletiles.DefaultField
+	if spec != "" {
+		field, err = parseField(spec)
+		if err != nil {
+			return err
+		}
+	}
+
+	rd, err := battletiles.NewRenderer(r, *info)
+	if err != nil {
+		return err
+	}
+
+	img, err := rd.Render(field)
+	if err != nil {
+		return err
+	}
+
+	if err := func() error {
+		f, err := os.Create(outFn + ".png")
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return png.Encode(f, img)
+	}(); err != nil {
+		return err
+	}
+
+	anim, err := rd.RenderAnimation(field)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(outFn + ".gif")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return gifanim.WriteGIF(f, anim)
+}
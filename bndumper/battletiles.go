@@ -33,8 +33,8 @@ func dumpBattletiles(r io.ReadSeeker, outFn string) error {
 		return err
 	}
 
-	redPal, m := battletiles.ConsolidatePalbank(palbanks, battletiles.RedTileByIndex)
-	bluePal, _ := battletiles.ConsolidatePalbank(palbanks, battletiles.BlueTileByIndex)
+	redPal, m := info.Layout.ConsolidatePalbank(palbanks, false)
+	bluePal, _ := info.Layout.ConsolidatePalbank(palbanks, true)
 
 	tiles, err := battletiles.ReadTiles(r, *info)
 	if err != nil {
@@ -44,15 +44,17 @@ func dumpBattletiles(r io.ReadSeeker, outFn string) error {
 	img := image.NewPaletted(image.Rect(0, 0, 9*battletiles.Width, 200*battletiles.Height), nil)
 
 	idx := 0
-	for j, tileImg := range tiles {
-		for _, pIndex := range battletiles.RedTileByIndex[j/3] {
-			tileImgCopy := battletiles.ShiftPalette(tileImg, m[pIndex])
+	for _, t := range info.Layout.PanelTypes {
+		for _, tileImg := range tiles[t] {
+			for _, pIndex := range info.Layout.Palbanks(t, false) {
+				tileImgCopy := battletiles.ShiftPalette(tileImg, m[pIndex])
 
-			x := (idx % 9) * battletiles.Width
-			y := (idx / 9) * battletiles.Height
+				x := (idx % 9) * battletiles.Width
+				y := (idx / 9) * battletiles.Height
 
-			paletted.DrawOver(img, image.Rect(x, y, x+battletiles.Width, y+battletiles.Height), tileImgCopy, image.Point{})
-			idx++
+				paletted.DrawOver(img, image.Rect(x, y, x+battletiles.Width, y+battletiles.Height), tileImgCopy, image.Point{})
+				idx++
+			}
 		}
 	}
 	img = img.SubImage(paletted.FindTrim(img)).(*image.Paletted)
@@ -116,7 +118,7 @@ func dumpBattletiles(r io.ReadSeeker, outFn string) error {
 				buf.WriteString("fctrl")
 				buf.WriteByte('\x00')
 				buf.WriteByte('\xff')
-				for tileIdx, fi := range battletiles.FrameInfos {
+				for tileIdx, fi := range info.Layout.FrameInfos() {
 					action := uint8(0)
 					if fi.IsEnd {
 						action = 0x01
@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"image/color"
 	"image/draw"
 	"image/png"
 	"io"
+	"log"
 	"os"
 
 	"github.com/schollz/progressbar/v3"
@@ -19,17 +21,12 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-func dumpChips(r io.ReadSeeker, chipsOutFn string, iconsOutFn string) error {
+func dumpChips(r io.ReadSeeker, chipsOutFn string, iconsOutFn string, chipInfosOutFn string, indexed bool) error {
 	romID, err := gbarom.ReadROMID(r)
 	if err != nil {
 		return err
 	}
 
-	romTitle, err := gbarom.ReadROMTitle(r)
-	if err != nil {
-		return err
-	}
-
 	info := chips.FindROMInfo(romID)
 	if info == nil {
 		return errors.New("unsupported game")
@@ -53,19 +50,50 @@ func dumpChips(r io.ReadSeeker, chipsOutFn string, iconsOutFn string) error {
 		chipInfos[i] = ci
 	}
 
+	if err := func() error {
+		f, err := os.Create(chipInfosOutFn)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return chips.WriteChipInfosJSON(f, chipInfos)
+	}(); err != nil {
+		return err
+	}
+
 	iconPalette, err := chips.ReadChipIconPalette(r, *info)
 	if err != nil {
 		return err
 	}
 
-	ereaderGigaPalette := chips.EReaderGigaPalette(romTitle)
+	ramPalettes, err := chips.FindRAMPalettes(r, chipInfos)
+	if err != nil {
+		if !errors.Is(err, chips.ErrUnresolvedRAMPointer) {
+			return err
+		}
+		log.Printf("%s", err)
+	}
 
 	bar2 := progressbar.Default(int64(len(chipInfos)))
 	bar2.Describe("dump")
 
 	numRows := (len(chipInfos) + 10 - 1) / 10
 
-	img := image.NewRGBA(image.Rect(0, 0, chips.Width*10, chips.Height*numRows))
+	var img draw.Image = image.NewRGBA(image.Rect(0, 0, chips.Width*10, chips.Height*numRows))
+
+	// In indexed mode every chip keeps its own 16 color palette: the sheet is drawn with palette indexes only, and each
+	// distinct palette is stored as a palbank.
+	var indexedImg *image.Paletted
+	var palbanks []color.Palette
+	chipPalbanks := make([]int, len(chipInfos))
+	for i := range chipPalbanks {
+		chipPalbanks[i] = -1
+	}
+	if indexed {
+		indexedImg = image.NewPaletted(img.Bounds(), nil)
+		img = indexedImg
+	}
 	iconsImg := image.NewPaletted(image.Rect(0, 0, chips.IconWidth*10, chips.IconHeight*numRows), iconPalette)
 
 	for i, ci := range chipInfos {
@@ -82,14 +110,41 @@ func dumpChips(r io.ReadSeeker, chipsOutFn string, iconsOutFn string) error {
 
 		paletted.DrawOver(iconsImg, image.Rect(x*chips.IconWidth, y*chips.IconHeight, (x+1)*chips.IconWidth, (y+1)*chips.IconHeight), chipIconImg, image.Point{})
 
-		chipImg, err := chips.ReadChipImage(r, ci, ereaderGigaPalette)
+		chipImg, err := chips.ReadChipImage(r, ci, ramPalettes)
 		if err != nil {
+			if errors.Is(err, chips.ErrUnresolvedRAMPointer) {
+				log.Printf("error reading chip %04d: %s", i, err)
+				continue
+			}
 			return err
 		}
 
+		if indexedImg != nil {
+			chipPalbanks[i] = palbankIndex(palbanks, chipImg.Palette)
+			if chipPalbanks[i] == -1 {
+				chipPalbanks[i] = len(palbanks)
+				palbanks = append(palbanks, chipImg.Palette)
+			}
+
+			paletted.DrawOver(indexedImg, image.Rect(x*chips.Width, y*chips.Height, (x+1)*chips.Width, (y+1)*chips.Height), chipImg, image.Point{})
+			continue
+		}
+
 		draw.Draw(img, image.Rect(x*chips.Width, y*chips.Height, (x+1)*chips.Width, (y+1)*chips.Height), chipImg, image.Point{}, draw.Over)
 	}
 
+	if indexedImg != nil {
+		if len(palbanks) > 0 {
+			indexedImg.Palette = palbanks[0]
+		} else {
+			// No chip could be read, so there's no palette to show the sheet with.
+			indexedImg.Palette = make(color.Palette, 16)
+			for i := range indexedImg.Palette {
+				indexedImg.Palette[i] = color.RGBA{}
+			}
+		}
+	}
+
 	if err := func() error {
 		f, err := os.Create(chipsOutFn)
 		if err != nil {
@@ -155,6 +210,37 @@ func dumpChips(r io.ReadSeeker, chipsOutFn string, iconsOutFn string) error {
 					}
 				}
 
+				if indexedImg != nil {
+					for k, palbank := range palbanks {
+						var buf bytes.Buffer
+						buf.WriteString(fmt.Sprintf("palbank%d", k))
+						buf.WriteByte('\x00')
+						buf.WriteByte('\x08')
+						for _, c := range palbank {
+							binary.Write(&buf, binary.LittleEndian, c.(color.RGBA))
+							buf.WriteByte('\xff')
+							buf.WriteByte('\xff')
+						}
+						if err := pngw.WriteChunk(int32(buf.Len()), "sPLT", bytes.NewBuffer(buf.Bytes())); err != nil {
+							return err
+						}
+					}
+
+					{
+						var buf bytes.Buffer
+						buf.WriteString("palidx")
+						buf.WriteByte('\x00')
+						buf.WriteByte('\xff')
+						for _, palbank := range chipPalbanks {
+							// Chips that could not be read are written as 0xffff.
+							binary.Write(&buf, binary.LittleEndian, uint16(palbank))
+						}
+						if err := pngw.WriteChunk(int32(buf.Len()), "zTXt", bytes.NewBuffer(buf.Bytes())); err != nil {
+							return err
+						}
+					}
+				}
+
 				metaWritten = true
 			}
 
@@ -264,3 +350,24 @@ func dumpChips(r io.ReadSeeker, chipsOutFn string, iconsOutFn string) error {
 
 	return nil
 }
+
+func palbankIndex(palbanks []color.Palette, palette color.Palette) int {
+	for i, palbank := range palbanks {
+		if len(palbank) != len(palette) {
+			continue
+		}
+
+		same := true
+		for j := range palbank {
+			if palbank[j] != palette[j] {
+				same = false
+				break
+			}
+		}
+
+		if same {
+			return i
+		}
+	}
+	return -1
+}
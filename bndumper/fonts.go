@@ -1,16 +1,19 @@
 package main
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"image"
 	"io"
 	"os"
-	"strconv"
+	"path/filepath"
+	"strings"
 
 	"github.com/murkland/bnrom/fonts"
 	"github.com/murkland/bnrom/fonts/bdf"
+	"github.com/murkland/bnrom/fonts/bmfont"
+	"github.com/murkland/bnrom/fonts/ttf"
+	"github.com/murkland/bnrom/text"
 	"github.com/murkland/gbarom"
 )
 
@@ -20,203 +23,196 @@ func dumpFonts(r io.ReadSeeker, outFn string) error {
 		return err
 	}
 
-	romTitle, err := gbarom.ReadROMTitle(r)
-	if err != nil {
-		return err
-	}
-
-	info := fonts.FindROMInfo(romID, romTitle)
+	info := fonts.FindROMInfo(romID)
 	if info == nil {
 		return errors.New("unsupported game")
 	}
 
+	if *tblF != "" {
+		charmap, _, err := text.LoadCharmap(romID, *tblF)
+		if err != nil {
+			return err
+		}
+		info.Charmap = charmap
+	}
+
 	os.Mkdir(outFn, 0o700)
 
-	if _, err := r.Seek(info.TinyOffset, io.SeekStart); err != nil {
-		return fmt.Errorf("%w while seeking to tiny font pointer", err)
+	tinyFont, err := fonts.ReadTinyFont(r, *info)
+	if err != nil {
+		return fmt.Errorf("%w while reading tiny font", err)
 	}
 
-	if err := dumpTinyFont(r, outFn+"/tinynum.bdf"); err != nil {
+	if err := dumpBDFFont(tinyFont, tinyProperties, outFn+"/tinynum.bdf"); err != nil {
 		return fmt.Errorf("%w while dumping tiny font", err)
 	}
 
-	if _, err := r.Seek(info.TallOffset, io.SeekStart); err != nil {
-		return fmt.Errorf("%w while seeking to tall font pointer", err)
+	if err := dumpBMFont(tinyFont, "tinynum", 14, outFn); err != nil {
+		return fmt.Errorf("%w while dumping tiny font as bmfont", err)
 	}
 
-	if err := dumpTallFont(r, info.Charmap, outFn+"/tall.bdf"); err != nil {
-		return fmt.Errorf("%w while dumping tall font", err)
+	if err := dumpTTFFont(tinyFont, "Murkland Tiny", tinyProperties, outFn+"/tinynum.ttf"); err != nil {
+		return fmt.Errorf("%w while dumping tiny font as truetype", err)
 	}
 
-	if err := dumpTall2Font(r, info, outFn+"/tall2.bdf"); err != nil {
-		return fmt.Errorf("%w while dumping tall font", err)
+	tallFont, err := fonts.ReadTallFont(r, *info)
+	if err != nil {
+		return fmt.Errorf("%w while reading tall font", err)
 	}
 
-	return nil
-}
-
-func dumpTinyFont(r io.ReadSeeker, outFn string) error {
-	const n = 10
-
-	var offsets []int64
-	for i := 0; i < n; i++ {
-		var offset uint32
-		if err := binary.Read(r, binary.LittleEndian, &offset); err != nil {
-			return fmt.Errorf("%w while reading offset to tiny font", err)
-		}
-		offsets = append(offsets, int64(offset&^0x08000000))
+	if err := dumpBDFFont(tallFont, tallProperties, outFn+"/tall.bdf"); err != nil {
+		return fmt.Errorf("%w while dumping tall font", err)
 	}
 
-	glyphs := make([]*image.Alpha, len(offsets))
-
-	for i, offset := range offsets {
-		if _, err := r.Seek(offset, io.SeekStart); err != nil {
-			return fmt.Errorf("%w while seeking to tiny font pointer", err)
-		}
-
-		glyph, err := fonts.ReadGlyph(r, 5)
-		if err != nil {
-			return fmt.Errorf("%w while reading tiny font", err)
-		}
+	if err := dumpBMFont(tallFont, "tall", 14, outFn); err != nil {
+		return fmt.Errorf("%w while dumping tall font as bmfont", err)
+	}
 
-		glyphs[i] = glyph
+	if err := dumpTTFFont(tallFont, "Murkland Tall", tallProperties, outFn+"/tall.ttf"); err != nil {
+		return fmt.Errorf("%w while dumping tall font as truetype", err)
 	}
 
-	outF, err := os.Create(outFn)
+	tall2Font, err := fonts.ReadTall2Font(r, *info)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w while reading tall2 font", err)
 	}
-	defer outF.Close()
 
-	p := bdf.Properties{
-		XLFD:      "-murkland-tiny-medium-r-normal--16-160-75-75-c-80-iso10646-1",
-		Size:      16,
-		DPI:       image.Point{75, 75},
-		BPP:       4,
-		BBox:      image.Rect(0, 0, 8, 16),
-		Ascent:    12,
-		Descent:   2,
-		NumGlyphs: len(glyphs),
-	}
-	if err := bdf.WriteProperties(outF, p); err != nil {
-		return fmt.Errorf("%w while writing bdf properties", err)
+	if err := dumpBDFFont(tall2Font, tall2Properties, outFn+"/tall2.bdf"); err != nil {
+		return fmt.Errorf("%w while dumping tall2 font", err)
 	}
 
-	for i, glyph := range glyphs {
-		if err := bdf.WriteGlyph(outF, p, 8, rune(strconv.Itoa(i)[0]), glyph); err != nil {
-			return fmt.Errorf("%w while writing bdf properties", err)
-		}
+	if err := dumpBMFont(tall2Font, "tall2", 10, outFn); err != nil {
+		return fmt.Errorf("%w while dumping tall2 font as bmfont", err)
 	}
 
-	if err := bdf.WriteTrailer(outF); err != nil {
-		return fmt.Errorf("%w while writing bdf trailer", err)
+	if err := dumpTTFFont(tall2Font, "Murkland Tall2", tall2Properties, outFn+"/tall2.ttf"); err != nil {
+		return fmt.Errorf("%w while dumping tall2 font as truetype", err)
 	}
 
 	return nil
 }
 
-func dumpTallFont(r io.ReadSeeker, charmap []rune, outFn string) error {
-	var offset uint32
-	if err := binary.Read(r, binary.LittleEndian, &offset); err != nil {
-		return fmt.Errorf("%w while reading offset to tall font", err)
-	}
-
-	if _, err := r.Seek(int64(offset&^0x08000000), io.SeekStart); err != nil {
-		return fmt.Errorf("%w while seeking to tall font pointer", err)
+// dumpBMFont writes a font as name.fnt and name_0.png in outDir. base is the distance from the top of a line to the
+// baseline.
+func dumpBMFont(font *fonts.Font, name string, base int, outDir string) error {
+	fntF, err := os.Create(filepath.Join(outDir, name+".fnt"))
+	if err != nil {
+		return err
 	}
+	defer fntF.Close()
 
-	outF, err := os.Create(outFn)
+	pageFn := name + "_0.png"
+	pageF, err := os.Create(filepath.Join(outDir, pageFn))
 	if err != nil {
 		return err
 	}
-	defer outF.Close()
+	defer pageF.Close()
 
-	p := bdf.Properties{
-		XLFD:      "-murkland-tall-medium-r-normal--16-160-75-75-c-80-iso10646-1",
-		Size:      16,
-		DPI:       image.Point{75, 75},
-		BPP:       4,
-		BBox:      image.Rect(0, 0, 8, 16),
-		Ascent:    12,
-		Descent:   2,
-		NumGlyphs: 448,
-	}
-	if err := bdf.WriteProperties(outF, p); err != nil {
-		return fmt.Errorf("%w while writing bdf properties", err)
+	glyphs := make([]bmfont.Glyph, len(font.Glyphs))
+	for i, glyph := range font.Glyphs {
+		glyphs[i] = bmfont.Glyph{Codepoint: font.Charmap[i], XAdvance: font.Widths[i], Image: glyph}
 	}
 
-	for i := 0; i < p.NumGlyphs; i++ {
-		glyph, err := fonts.ReadGlyph(r, 1)
-		if err != nil {
-			return fmt.Errorf("%w while reading tall font glyph %d", err, i)
-		}
+	info := bmfont.Info{Face: name, Size: font.LineHeight, LineHeight: font.LineHeight, Base: base}
+	return bmfont.Write(fntF, pageF, pageFn, info, glyphs)
+}
+
+var (
+	tinyProperties = bdf.Properties{
+		Size:        16,
+		DPI:         image.Point{72, 72},
+		BPP:         4,
+		BBox:        image.Rect(0, -2, 8, 14),
+		Ascent:      14,
+		Descent:     2,
+		Family:      "tiny",
+		Weight:      "Medium",
+		DefaultChar: '0',
+	}
+
+	tallProperties = bdf.Properties{
+		Size:        16,
+		DPI:         image.Point{72, 72},
+		BPP:         4,
+		BBox:        image.Rect(0, -2, 8, 14),
+		Ascent:      14,
+		Descent:     2,
+		Family:      "tall",
+		Weight:      "Medium",
+		DefaultChar: ' ',
+	}
+
+	tall2Properties = bdf.Properties{
+		Size:        12,
+		DPI:         image.Point{72, 72},
+		BPP:         4,
+		BBox:        image.Rect(0, -2, 16, 10),
+		Ascent:      10,
+		Descent:     2,
+		Family:      "tall2",
+		Weight:      "Thin",
+		DefaultChar: ' ',
+	}
+)
 
-		if err := bdf.WriteGlyph(outF, p, 8, charmap[i], glyph); err != nil {
-			return fmt.Errorf("%w while writing bdf properties", err)
+// xlfd names a font from its properties. Fonts whose glyphs all advance by the cell width are character cell fonts,
+// others are proportional.
+func xlfd(font *fonts.Font, p bdf.Properties) string {
+	spacing := "c"
+	total := 0
+	for _, width := range font.Widths {
+		if width != p.BBox.Dx() {
+			spacing = "p"
 		}
+		total += width
 	}
 
-	if err := bdf.WriteTrailer(outF); err != nil {
-		return fmt.Errorf("%w while writing bdf trailer", err)
+	avgWidth := 0
+	if len(font.Widths) > 0 {
+		avgWidth = (total*10 + len(font.Widths)/2) / len(font.Widths)
 	}
 
-	return nil
+	return fmt.Sprintf("-murkland-%s-%s-r-normal--%d-%d-%d-%d-%s-%d-iso10646-1", p.Family, strings.ToLower(p.Weight), p.Ascent+p.Descent, p.Size*10, p.DPI.X, p.DPI.Y, spacing, avgWidth)
 }
 
-func dumpTall2Font(r io.ReadSeeker, info *fonts.ROMInfo, outFn string) error {
+func dumpBDFFont(font *fonts.Font, p bdf.Properties, outFn string) error {
 	outF, err := os.Create(outFn)
 	if err != nil {
 		return err
 	}
 	defer outF.Close()
 
-	p := bdf.Properties{
-		XLFD:      "-murkland-tall2-thin-r-normal--16-160-75-75-c-80-iso10646-1",
-		Size:      16,
-		DPI:       image.Point{75, 75},
-		BPP:       4,
-		BBox:      image.Rect(0, 0, 16, 12),
-		Ascent:    12,
-		Descent:   2,
-		NumGlyphs: 448,
-	}
-	if err := bdf.WriteProperties(outF, p); err != nil {
+	p.XLFD = xlfd(font, p)
+	p.NumGlyphs = len(font.Glyphs)
+	bw, err := bdf.NewWriter(outF, p)
+	if err != nil {
 		return fmt.Errorf("%w while writing bdf properties", err)
 	}
 
-	if _, err := r.Seek(info.Tall2MetricsOffset, io.SeekStart); err != nil {
-		return fmt.Errorf("%w while seeking to tall font pointer", err)
-	}
-
-	metrics, err := fonts.ReadMetrics(r, p.NumGlyphs)
-	if err != nil {
-		return fmt.Errorf("%w while reading metrics properties", err)
+	for i, glyph := range font.Glyphs {
+		if err := bw.WriteGlyph(font.Widths[i], font.Charmap[i], glyph); err != nil {
+			return fmt.Errorf("%w while writing bdf glyph %d", err, i)
+		}
 	}
 
-	if _, err := r.Seek(info.Tall2Offset+0x60, io.SeekStart); err != nil {
-		return fmt.Errorf("%w while seeking to tall font pointer", err)
+	if err := bw.Close(); err != nil {
+		return fmt.Errorf("%w while writing bdf trailer", err)
 	}
 
-	for i := 0; i < p.NumGlyphs; i++ {
-		var glyph *image.Alpha
-		if i > 0 {
-			var err error
-			glyph, err = fonts.Read16x12Glyph(r)
-			if err != nil {
-				return err
-			}
-		} else {
-			glyph = image.NewAlpha(p.BBox)
-		}
+	return nil
+}
 
-		if err := bdf.WriteGlyph(outF, p, metrics[i], info.Charmap[i], glyph); err != nil {
-			return fmt.Errorf("%w while writing bdf properties", err)
-		}
+func dumpTTFFont(font *fonts.Font, family string, p bdf.Properties, outFn string) error {
+	outF, err := os.Create(outFn)
+	if err != nil {
+		return err
 	}
+	defer outF.Close()
 
-	if err := bdf.WriteTrailer(outF); err != nil {
-		return fmt.Errorf("%w while writing bdf trailer", err)
+	glyphs := make([]ttf.Glyph, len(font.Glyphs))
+	for i, glyph := range font.Glyphs {
+		glyphs[i] = ttf.Glyph{Codepoint: font.Charmap[i], Advance: font.Widths[i], Image: glyph}
 	}
 
-	return nil
+	return ttf.Write(outF, family, p, glyphs)
 }
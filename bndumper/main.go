@@ -11,8 +11,14 @@ import (
 var (
 	dumpSpritesF     = flag.Bool("dump_sprites", true, "dump sprites")
 	dumpBattletilesF = flag.Bool("dump_battletiles", true, "dump battletiles")
+	battlefieldF     = flag.String("battlefield", "", "field to render with the battletiles, as rows separated by / of six comma-separated panels like r:normal or b:poison (owner and panel type)")
+	dumpPalanimF     = flag.Bool("dump_palanim", true, "dump palette animations")
 	dumpChipsF       = flag.Bool("dump_chips", true, "dump chips")
+	chipsIndexedF    = flag.Bool("chips_indexed", false, "dump chips as an indexed sheet that keeps each chip's palette")
 	dumpFontsF       = flag.Bool("dump_fonts", true, "dump fonts")
+	dumpTblF         = flag.Bool("dump_tbl", true, "dump the charmap as a thingy table")
+	tblF             = flag.String("tbl", "", "thingy table to use instead of the built-in charmap")
+	textArchivesF    = flag.String("text_archives", "", "comma-separated offsets of pointers to text archives to dump")
 )
 
 type fctrlFrameInfo struct {
@@ -54,11 +60,23 @@ func main() {
 		if err := dumpBattletiles(f, "battletiles.png"); err != nil {
 			log.Fatalf("%s", err)
 		}
+
+		log.Printf("Rendering battlefield...")
+		if err := dumpBattlefield(f, *battlefieldF, "battlefield"); err != nil {
+			log.Fatalf("%s", err)
+		}
+	}
+
+	if *dumpPalanimF {
+		log.Printf("Dumping palette animations...")
+		if err := dumpPalanim(f, "palanim"); err != nil {
+			log.Fatalf("%s", err)
+		}
 	}
 
 	if *dumpChipsF {
 		log.Printf("Dumping chips...")
-		if err := dumpChips(f, "chips.png", "chipicons.png"); err != nil {
+		if err := dumpChips(f, "chips.png", "chipicons.png", "chipinfos.json", *chipsIndexedF); err != nil {
 			log.Fatalf("%s", err)
 		}
 	}
@@ -70,5 +88,24 @@ func main() {
 		}
 	}
 
+	if *dumpTblF {
+		log.Printf("Dumping charmap table...")
+		if err := dumpTbl(f, "charmap.tbl"); err != nil {
+			log.Fatalf("%s", err)
+		}
+	}
+
+	if *textArchivesF != "" {
+		log.Printf("Dumping text...")
+		ptrOffsets, err := parsePtrOffsets(*textArchivesF)
+		if err != nil {
+			log.Fatalf("%s", err)
+		}
+
+		if err := dumpText(f, "text", ptrOffsets); err != nil {
+			log.Fatalf("%s", err)
+		}
+	}
+
 	log.Printf("Done!")
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/murkland/bnrom/battletiles"
+	"github.com/murkland/bnrom/gifanim"
+	"github.com/murkland/bnrom/palanim"
+	"github.com/murkland/gbarom"
+)
+
+func dumpPalanim(r io.ReadSeeker, outFn string) error {
+	romID, err := gbarom.ReadROMID(r)
+	if err != nil {
+		return err
+	}
+
+	info := battletiles.FindROMInfo(romID)
+	if info == nil {
+		return errors.New("unsupported game")
+	}
+
+	palbanks, err := battletiles.ReadPalbanks(r, *info)
+	if err != nil {
+		return err
+	}
+
+	anims, err := palanim.FromBattletiles(palbanks, info.Layout)
+	if err != nil {
+		return err
+	}
+
+	os.Mkdir(outFn, 0o700)
+
+	if err := func() error {
+		f, err := os.Create(fmt.Sprintf("%s/battletiles.json", outFn))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return palanim.WriteJSON(f, anims)
+	}(); err != nil {
+		return err
+	}
+
+	img, err := palanim.Swatches(anims)
+	if err != nil {
+		return err
+	}
+
+	seq, err := palanim.Render(img, anims)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(fmt.Sprintf("%s/battletiles.gif", outFn))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return gifanim.WriteGIF(f, seq)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/murkland/bnrom/text"
+	"github.com/murkland/bnrom/text/tbl"
+	"github.com/murkland/gbarom"
+)
+
+func parsePtrOffsets(s string) ([]int64, error) {
+	var ptrOffsets []int64
+	for _, field := range strings.Split(s, ",") {
+		if field == "" {
+			continue
+		}
+
+		ptrOffset, err := strconv.ParseInt(field, 0, 64)
+		if err != nil {
+			return nil, err
+		}
+		ptrOffsets = append(ptrOffsets, ptrOffset)
+	}
+	return ptrOffsets, nil
+}
+
+func dumpTbl(r io.ReadSeeker, outFn string) error {
+	romID, err := gbarom.ReadROMID(r)
+	if err != nil {
+		return err
+	}
+
+	charmap, dialect, err := text.LoadCharmap(romID, *tblF)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(outFn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return tbl.Write(f, text.ToTable(charmap, *dialect))
+}
+
+func dumpText(r io.ReadSeeker, outFn string, ptrOffsets []int64) error {
+	romID, err := gbarom.ReadROMID(r)
+	if err != nil {
+		return err
+	}
+
+	charmap, dialect, err := text.LoadCharmap(romID, *tblF)
+	if err != nil {
+		return err
+	}
+
+	os.Mkdir(outFn, 0o700)
+
+	for _, ptrOffset := range ptrOffsets {
+		if _, err := r.Seek(ptrOffset, io.SeekStart); err != nil {
+			return fmt.Errorf("%w while seeking to text archive pointer at 0x%08x", err, ptrOffset)
+		}
+
+		var ptr uint32
+		if err := binary.Read(r, binary.LittleEndian, &ptr); err != nil {
+			return fmt.Errorf("%w while reading text archive pointer at 0x%08x", err, ptrOffset)
+		}
+
+		archive, err := text.ReadArchive(r, ptr, charmap, *dialect)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(fmt.Sprintf("%s/0x%08x.txt", outFn, ptrOffset), []byte(text.FormatArchive(archive, *dialect)), 0o600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
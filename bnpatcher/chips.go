@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/murkland/bnrom/chips"
+	"github.com/murkland/gbarom"
+)
+
+func patchChips(rom []byte, chipInfosFn string) error {
+	r := bytes.NewReader(rom)
+
+	romID, err := gbarom.ReadROMID(r)
+	if err != nil {
+		return err
+	}
+
+	info := chips.FindROMInfo(romID)
+	if info == nil {
+		return errors.New("unsupported game")
+	}
+
+	chipInfos, err := chips.ReadChipInfos(r, *info)
+	if err != nil {
+		return err
+	}
+
+	edited := make([]chips.ChipInfo, len(chipInfos))
+	copy(edited, chipInfos)
+
+	if err := func() error {
+		f, err := os.Open(chipInfosFn)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		switch strings.ToLower(filepath.Ext(chipInfosFn)) {
+		case ".json":
+			return chips.ApplyChipInfosJSON(f, edited)
+		case ".csv":
+			return chips.ApplyChipInfosCSV(f, edited)
+		}
+		return fmt.Errorf("unsupported chip info format: %s", chipInfosFn)
+	}(); err != nil {
+		return err
+	}
+
+	n := 0
+	for i, ci := range edited {
+		if ci == chipInfos[i] {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := chips.WriteChipInfo(&buf, ci); err != nil {
+			return fmt.Errorf("%w while encoding chip %04d", err, i)
+		}
+
+		copy(rom[info.Offset+int64(i)*chips.ChipInfoSize:], buf.Bytes())
+		n++
+	}
+
+	roundTripped, err := chips.ReadChipInfos(bytes.NewReader(rom), *info)
+	if err != nil {
+		return fmt.Errorf("%w while rereading patched chips", err)
+	}
+
+	for i, ci := range roundTripped {
+		if ci != edited[i] {
+			return fmt.Errorf("chip %04d did not round-trip", i)
+		}
+	}
+
+	log.Printf("Patched %d chips", n)
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/murkland/bnrom/fonts"
+	"github.com/murkland/bnrom/fonts/bdf"
+	"github.com/murkland/bnrom/text"
+	"github.com/murkland/gbarom"
+)
+
+func readBDF(fn string) (*bdf.Font, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	bf, err := bdf.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w while reading %s", err, fn)
+	}
+
+	return bf, nil
+}
+
+// patchFonts reinserts tall.bdf and tall2.bdf from fontsDir, whichever exist. Glyphs are matched to the rom's by their
+// codepoint in the charmap.
+func patchFonts(rom []byte, fontsDir string) error {
+	romID, err := gbarom.ReadROMID(bytes.NewReader(rom))
+	if err != nil {
+		return err
+	}
+
+	info := fonts.FindROMInfo(romID)
+	if info == nil {
+		return errors.New("unsupported game")
+	}
+
+	if *tblF != "" {
+		charmap, _, err := text.LoadCharmap(romID, *tblF)
+		if err != nil {
+			return err
+		}
+		info.Charmap = charmap
+	}
+
+	for _, f := range []struct {
+		name  string
+		read  func(r io.ReadSeeker, ri fonts.ROMInfo) (*fonts.Font, error)
+		patch func(rom []byte, ri fonts.ROMInfo, f *fonts.Font) error
+	}{
+		{"tall", fonts.ReadTallFont, fonts.PatchTallFont},
+		{"tall2", fonts.ReadTall2Font, fonts.PatchTall2Font},
+	} {
+		fn := filepath.Join(fontsDir, f.name+".bdf")
+		if _, err := os.Stat(fn); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		bf, err := readBDF(fn)
+		if err != nil {
+			return err
+		}
+
+		font, err := f.read(bytes.NewReader(rom), *info)
+		if err != nil {
+			return fmt.Errorf("%w while reading %s font", err, f.name)
+		}
+
+		if err := font.ApplyBDF(bf); err != nil {
+			return fmt.Errorf("%w while applying %s", err, fn)
+		}
+
+		if err := f.patch(rom, *info, font); err != nil {
+			return fmt.Errorf("%w while patching %s font", err, f.name)
+		}
+
+		log.Printf("Applied %d glyphs from %s.", len(bf.Glyphs), fn)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/murkland/bnrom/patch"
+	"github.com/murkland/gbarom"
+)
+
+var (
+	chipsF = flag.String("chips", "", "edited chip infos to apply (.json or .csv)")
+	textF  = flag.String("text", "", "directory of edited text archives to reinsert, as dumped by bndumper")
+	fontsF = flag.String("fonts", "", "directory of edited fonts to reinsert (tall.bdf, tall2.bdf), as dumped by bndumper")
+	tblF   = flag.String("tbl", "", "thingy table to use instead of the built-in charmap")
+	outF   = flag.String("out", "patched.gba", "where to write the result: .ips or .bps writes a patch, anything else a patched rom")
+)
+
+func writeOutput(outFn string, source []byte, target []byte) error {
+	f, err := os.Create(outFn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(outFn)) {
+	case ".ips":
+		return patch.WriteIPS(f, source, target)
+	case ".bps":
+		return patch.WriteBPS(f, source, target)
+	}
+
+	if _, err := f.Write(target); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func main() {
+	flag.Parse()
+
+	source, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+
+	romTitle, err := gbarom.ReadROMTitle(bytes.NewReader(source))
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+
+	log.Printf("Game title: %s", romTitle)
+
+	target := make([]byte, len(source))
+	copy(target, source)
+
+	if *chipsF != "" {
+		log.Printf("Patching chips...")
+		if err := patchChips(target, *chipsF); err != nil {
+			log.Fatalf("%s", err)
+		}
+	}
+
+	if *textF != "" {
+		log.Printf("Patching text...")
+		if err := patchText(target, *textF); err != nil {
+			log.Fatalf("%s", err)
+		}
+	}
+
+	if *fontsF != "" {
+		log.Printf("Patching fonts...")
+		if err := patchFonts(target, *fontsF); err != nil {
+			log.Fatalf("%s", err)
+		}
+	}
+
+	if err := writeOutput(*outF, source, target); err != nil {
+		log.Fatalf("%s", err)
+	}
+
+	log.Printf("Done!")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/murkland/bnrom/text"
+	"github.com/murkland/gbarom"
+)
+
+// patchText reinserts every text archive in textDir. Files are named after the offset of the pointer to the archive
+// they replace, as written by bndumper.
+func patchText(rom []byte, textDir string) error {
+	r := bytes.NewReader(rom)
+
+	romID, err := gbarom.ReadROMID(r)
+	if err != nil {
+		return err
+	}
+
+	charmap, dialect, err := text.LoadCharmap(romID, *tblF)
+	if err != nil {
+		return err
+	}
+
+	fns, err := filepath.Glob(filepath.Join(textDir, "0x*.txt"))
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range fns {
+		ptrOffset, err := strconv.ParseInt(strings.TrimSuffix(filepath.Base(fn), ".txt"), 0, 64)
+		if err != nil {
+			return fmt.Errorf("%w while parsing pointer offset of %s", err, fn)
+		}
+
+		raw, err := os.ReadFile(fn)
+		if err != nil {
+			return err
+		}
+
+		archive, err := text.ParseArchive(string(raw), *dialect)
+		if err != nil {
+			return fmt.Errorf("%w while parsing %s", err, fn)
+		}
+
+		if ptrOffset < 0 || ptrOffset+4 > int64(len(rom)) {
+			return fmt.Errorf("pointer offset of %s out of range", fn)
+		}
+
+		old, err := text.ReadArchive(bytes.NewReader(rom), binary.LittleEndian.Uint32(rom[ptrOffset:]), charmap, *dialect)
+		if err != nil {
+			return fmt.Errorf("%w while reading archive replaced by %s", err, fn)
+		}
+		archive.Size = old.Size
+
+		if err := text.Reinsert(rom, ptrOffset, archive, charmap, *dialect); err != nil {
+			return fmt.Errorf("%w while reinserting %s", err, fn)
+		}
+
+		log.Printf("Reinserted %s", fn)
+	}
+
+	return nil
+}
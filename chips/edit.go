@@ -0,0 +1,178 @@
+package chips
+
+import (
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+const ChipInfoSize = 0x2C
+
+func WriteChipInfo(w io.Writer, ci ChipInfo) error {
+	return binary.Write(w, binary.LittleEndian, &ci)
+}
+
+var ErrMissingID = errors.New("chips: record has no ID")
+
+func checkID(id int, chipInfos []ChipInfo) error {
+	if id < 0 || id >= len(chipInfos) {
+		return fmt.Errorf("chips: record ID %d out of range", id)
+	}
+	return nil
+}
+
+// WriteChipInfosJSON writes chip infos as a JSON array of objects keyed by field name, each with its chip ID.
+func WriteChipInfosJSON(w io.Writer, chipInfos []ChipInfo) error {
+	type record struct {
+		ID int
+		ChipInfo
+	}
+
+	records := make([]record, len(chipInfos))
+	for i, ci := range chipInfos {
+		records[i] = record{i, ci}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// ApplyChipInfosJSON applies a JSON array of edited records, as written by WriteChipInfosJSON, to chipInfos. Records
+// only need an ID and the fields being changed.
+func ApplyChipInfosJSON(r io.Reader, chipInfos []ChipInfo) error {
+	var records []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return fmt.Errorf("%w while decoding records", err)
+	}
+
+	for i, raw := range records {
+		var header struct {
+			ID *int
+		}
+		if err := json.Unmarshal(raw, &header); err != nil {
+			return fmt.Errorf("%w while decoding record %d", err, i)
+		}
+
+		if header.ID == nil {
+			return fmt.Errorf("%w while decoding record %d", ErrMissingID, i)
+		}
+
+		if err := checkID(*header.ID, chipInfos); err != nil {
+			return fmt.Errorf("%w while decoding record %d", err, i)
+		}
+
+		if err := json.Unmarshal(raw, &chipInfos[*header.ID]); err != nil {
+			return fmt.Errorf("%w while decoding record %d", err, i)
+		}
+	}
+
+	return nil
+}
+
+var chipInfoFields = func() []string {
+	t := reflect.TypeOf(ChipInfo{})
+	fields := make([]string, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		fields[i] = t.Field(i).Name
+	}
+	return fields
+}()
+
+// WriteChipInfosCSV writes chip infos as CSV with a header row of field names, led by an ID column.
+func WriteChipInfosCSV(w io.Writer, chipInfos []ChipInfo) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(append([]string{"ID"}, chipInfoFields...)); err != nil {
+		return err
+	}
+
+	for i, ci := range chipInfos {
+		v := reflect.ValueOf(ci)
+
+		row := []string{strconv.Itoa(i)}
+		for j := range chipInfoFields {
+			row = append(row, strconv.FormatUint(v.Field(j).Uint(), 10))
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ApplyChipInfosCSV applies CSV records, as written by WriteChipInfosCSV, to chipInfos. The header must have an ID
+// column; other columns are optional and empty cells are left unchanged. Values may be written in decimal or with a
+// 0x prefix.
+func ApplyChipInfosCSV(r io.Reader, chipInfos []ChipInfo) error {
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("%w while reading header", err)
+	}
+
+	idCol := -1
+	fieldIndexes := make([]int, len(header))
+	for i, name := range header {
+		fieldIndexes[i] = -1
+
+		if name == "ID" {
+			idCol = i
+			continue
+		}
+
+		field, ok := reflect.TypeOf(ChipInfo{}).FieldByName(name)
+		if !ok {
+			return fmt.Errorf("chips: unknown column %q", name)
+		}
+		fieldIndexes[i] = field.Index[0]
+	}
+
+	if idCol == -1 {
+		return ErrMissingID
+	}
+
+	for line := 1; ; line++ {
+		row, err := cr.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("%w while reading record %d", err, line)
+		}
+
+		id, err := strconv.Atoi(row[idCol])
+		if err != nil {
+			return fmt.Errorf("%w while reading ID of record %d", err, line)
+		}
+
+		if err := checkID(id, chipInfos); err != nil {
+			return fmt.Errorf("%w while reading record %d", err, line)
+		}
+
+		v := reflect.ValueOf(&chipInfos[id]).Elem()
+		for i, cell := range row {
+			if fieldIndexes[i] == -1 || cell == "" {
+				continue
+			}
+
+			field := v.Field(fieldIndexes[i])
+			x, err := strconv.ParseUint(cell, 0, field.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("%w while reading %s of record %d", err, header[i], line)
+			}
+			field.SetUint(x)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package chips
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func randomChipInfos(n int) []ChipInfo {
+	rng := rand.New(rand.NewSource(1))
+
+	chipInfos := make([]ChipInfo, n)
+	for i := range chipInfos {
+		v := reflect.ValueOf(&chipInfos[i]).Elem()
+		for j := 0; j < v.NumField(); j++ {
+			v.Field(j).SetUint(rng.Uint64() >> (64 - v.Field(j).Type().Bits()))
+		}
+	}
+	return chipInfos
+}
+
+func TestWriteChipInfo(t *testing.T) {
+	for _, ci := range randomChipInfos(10) {
+		var buf bytes.Buffer
+		if err := WriteChipInfo(&buf, ci); err != nil {
+			t.Fatalf("WriteChipInfo() = %v", err)
+		}
+
+		if buf.Len() != ChipInfoSize {
+			t.Errorf("WriteChipInfo() wrote %d bytes, want %d", buf.Len(), ChipInfoSize)
+		}
+
+		got, err := ReadChipInfo(&buf)
+		if err != nil {
+			t.Fatalf("ReadChipInfo() = %v", err)
+		}
+		if got != ci {
+			t.Errorf("ReadChipInfo(WriteChipInfo(%+v)) = %+v", ci, got)
+		}
+	}
+}
+
+func TestChipInfosJSONRoundTrip(t *testing.T) {
+	chipInfos := randomChipInfos(10)
+
+	var buf bytes.Buffer
+	if err := WriteChipInfosJSON(&buf, chipInfos); err != nil {
+		t.Fatalf("WriteChipInfosJSON() = %v", err)
+	}
+
+	got := make([]ChipInfo, len(chipInfos))
+	if err := ApplyChipInfosJSON(&buf, got); err != nil {
+		t.Fatalf("ApplyChipInfosJSON() = %v", err)
+	}
+	if !reflect.DeepEqual(got, chipInfos) {
+		t.Errorf("ApplyChipInfosJSON(WriteChipInfosJSON()) does not give the chip infos back")
+	}
+}
+
+func TestChipInfosCSVRoundTrip(t *testing.T) {
+	chipInfos := randomChipInfos(10)
+
+	var buf bytes.Buffer
+	if err := WriteChipInfosCSV(&buf, chipInfos); err != nil {
+		t.Fatalf("WriteChipInfosCSV() = %v", err)
+	}
+
+	got := make([]ChipInfo, len(chipInfos))
+	if err := ApplyChipInfosCSV(&buf, got); err != nil {
+		t.Fatalf("ApplyChipInfosCSV() = %v", err)
+	}
+	if !reflect.DeepEqual(got, chipInfos) {
+		t.Errorf("ApplyChipInfosCSV(WriteChipInfosCSV()) does not give the chip infos back")
+	}
+}
+
+func TestApplyChipInfosCSVPartial(t *testing.T) {
+	chipInfos := randomChipInfos(3)
+	want := append([]ChipInfo(nil), chipInfos...)
+	want[1].MB = 0x2A
+
+	if err := ApplyChipInfosCSV(bytes.NewBufferString("ID,MB,Damage\n1,0x2A,\n"), chipInfos); err != nil {
+		t.Fatalf("ApplyChipInfosCSV() = %v", err)
+	}
+	if !reflect.DeepEqual(chipInfos, want) {
+		t.Errorf("ApplyChipInfosCSV() changed more than MB of chip 1")
+	}
+}
@@ -1,7 +1,6 @@
 package chips
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
 	"image"
@@ -21,26 +20,6 @@ type ROMInfo struct {
 	IconPalOffset int64
 }
 
-func mustDecodePalette(raw []uint8) color.Palette {
-	r := bytes.NewBuffer(raw)
-
-	var palette color.Palette
-	for i := 0; i < 16; i++ {
-		var c uint16
-		if err := binary.Read(r, binary.LittleEndian, &c); err != nil {
-			panic(err)
-		}
-
-		palette = append(palette, bgr555.ToRGBA(c))
-	}
-
-	return palette
-}
-
-var falzarGigaPalette = mustDecodePalette([]uint8{0x00, 0x00, 0xDE, 0x7B, 0x74, 0x77, 0xCC, 0x49, 0xEB, 0x44, 0x07, 0x77, 0x43, 0x6E, 0x82, 0x55, 0xC1, 0x40, 0x3E, 0x1B, 0x59, 0x26, 0xB4, 0x09, 0xDD, 0x76, 0x7B, 0x55, 0x39, 0x20, 0x05, 0x18})
-var gregarGigaPalette = mustDecodePalette([]uint8{0x00, 0x00, 0xFF, 0x77, 0x9E, 0x47, 0x3F, 0x1F, 0x7D, 0x0A, 0x77, 0x0D, 0xF4, 0x04, 0x51, 0x00, 0x89, 0x10, 0xA3, 0x18, 0x5F, 0x4D, 0x87, 0x37, 0x90, 0x7F, 0xCC, 0x5A, 0x09, 0x36, 0x26, 0x21})
-var dblBeastPalette = mustDecodePalette([]uint8{0x7F, 0x7D, 0x9F, 0x13, 0x5E, 0x22, 0x1F, 0x0D, 0xB1, 0x00, 0xD0, 0x41, 0x0D, 0x3D, 0x30, 0x13, 0x99, 0x61, 0xFF, 0x77, 0xA8, 0x4E, 0xA8, 0x39, 0x03, 0x21, 0xF9, 0x5A, 0x30, 0x5F, 0x61, 0x0C})
-
 func FindROMInfo(romID string) *ROMInfo {
 	switch romID {
 	case "BR6E", "BR6P", "BR5E", "BR5P":
@@ -51,16 +30,6 @@ func FindROMInfo(romID string) *ROMInfo {
 	return nil
 }
 
-func EReaderGigaPalette(romTitle string) color.Palette {
-	switch romTitle {
-	case "ROCKEXE6_GXX":
-		return gregarGigaPalette
-	case "ROCKEXE6_RXX":
-		return falzarGigaPalette
-	}
-	return nil
-}
-
 type ChipInfo struct {
 	ChipCodes           uint32 // 0
 	AttackElement       uint8  // 4
@@ -101,10 +70,70 @@ func ReadChipInfo(r io.Reader) (ChipInfo, error) {
 	return d, nil
 }
 
+func ReadChipInfos(r io.ReadSeeker, ri ROMInfo) ([]ChipInfo, error) {
+	if _, err := r.Seek(ri.Offset, os.SEEK_SET); err != nil {
+		return nil, fmt.Errorf("%w while seeking to chip info offset", err)
+	}
+
+	chipInfos := make([]ChipInfo, ri.Count)
+	for i := 0; i < len(chipInfos); i++ {
+		ci, err := ReadChipInfo(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w while reading chip info %d", err, i)
+		}
+		chipInfos[i] = ci
+	}
+
+	return chipInfos, nil
+}
+
+// NoCode marks an unused slot in ChipInfo.ChipCodes.
+const NoCode = 0xFF
+
+// CodeAsterisk is the code that matches any other code.
+const CodeAsterisk = 26
+
+// Codes returns the codes the chip comes in, in the order they are stored.
+func (ci ChipInfo) Codes() []uint8 {
+	var codes []uint8
+	for i := 0; i < 4; i++ {
+		code := uint8(ci.ChipCodes >> (i * 8))
+		if code == NoCode {
+			continue
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// CodeRune returns the letter used to display a chip code.
+func CodeRune(code uint8) rune {
+	if code == CodeAsterisk {
+		return '*'
+	}
+	if code < CodeAsterisk {
+		return 'A' + rune(code)
+	}
+	return '?'
+}
+
 const Width = 7 * 8
 const Height = 6 * 8
 
-func ReadChipImage(r io.ReadSeeker, ci ChipInfo, ereaderGigaPalette color.Palette) (*image.Paletted, error) {
+func readPalette(r io.Reader) (color.Palette, error) {
+	var palette color.Palette
+	for i := 0; i < 16; i++ {
+		var c uint16
+		if err := binary.Read(r, binary.LittleEndian, &c); err != nil {
+			return nil, fmt.Errorf("%w while reading palette entry %d", err, i)
+		}
+
+		palette = append(palette, bgr555.ToRGBA(c))
+	}
+	return palette, nil
+}
+
+func ReadChipImage(r io.ReadSeeker, ci ChipInfo, ramPalettes RAMPalettes) (*image.Paletted, error) {
 	retOffset, err := r.Seek(0, os.SEEK_CUR)
 	if err != nil {
 		return nil, fmt.Errorf("%w while remembering offset", err)
@@ -129,25 +158,24 @@ func ReadChipImage(r io.ReadSeeker, ci ChipInfo, ereaderGigaPalette color.Palett
 		}
 	}
 
-	var palette color.Palette
-	if ci.ChipPalettePtr&0x08000000 == 0x08000000 {
-		if _, err := r.Seek(int64(ci.ChipPalettePtr & ^uint32(0x08000000)), os.SEEK_SET); err != nil {
-			return nil, fmt.Errorf("%w while seeking to palette pointer", err)
+	paletteOffset := int64(ci.ChipPalettePtr & ^uint32(0x08000000))
+	if isRAMPointer(ci.ChipPalettePtr) {
+		var ok bool
+		paletteOffset, ok = ramPalettes[ci.ChipPalettePtr]
+		if !ok {
+			return nil, fmt.Errorf("%w 0x%08x while resolving palette pointer", ErrUnresolvedRAMPointer, ci.ChipPalettePtr)
 		}
-		for i := 0; i < 16; i++ {
-			var c uint16
-			if err := binary.Read(r, binary.LittleEndian, &c); err != nil {
-				return nil, fmt.Errorf("%w while reading palette entry %d", err, i)
-			}
+	} else if !isROMPointer(ci.ChipPalettePtr) {
+		return nil, fmt.Errorf("invalid palette pointer 0x%08x", ci.ChipPalettePtr)
+	}
 
-			palette = append(palette, bgr555.ToRGBA(c))
-		}
-	} else {
-		if ci.ChipPalettePtr == 0x02000b10 {
-			palette = ereaderGigaPalette
-		} else if ci.ChipPalettePtr == 0x02000af0 {
-			palette = dblBeastPalette
-		}
+	if _, err := r.Seek(paletteOffset, os.SEEK_SET); err != nil {
+		return nil, fmt.Errorf("%w while seeking to palette pointer", err)
+	}
+
+	palette, err := readPalette(r)
+	if err != nil {
+		return nil, err
 	}
 
 	img.Palette = palette
@@ -0,0 +1,210 @@
+package chips
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/murkland/gbarom"
+)
+
+// RAMPalettes maps chip palette pointers that point into EWRAM to the ROM offsets the game copies them from.
+type RAMPalettes map[uint32]int64
+
+var ErrUnresolvedRAMPointer = errors.New("chips: unresolved RAM pointer")
+var ErrRAMPaletteMismatch = errors.New("chips: RAM palette does not match the known palette")
+
+// How many literal pool words on either side of a RAM pointer to consider as the copy source.
+const ramPaletteSearchWindow = 4
+
+// How far apart, in bytes, the instructions loading the copy's source and destination may be.
+const ramPaletteLoadWindow = 16
+
+// Palettes known to be copied to RAM, by game, used to check what FindRAMPalettes finds.
+var gregarGigaPalette = []uint8{0x00, 0x00, 0xFF, 0x77, 0x9E, 0x47, 0x3F, 0x1F, 0x7D, 0x0A, 0x77, 0x0D, 0xF4, 0x04, 0x51, 0x00, 0x89, 0x10, 0xA3, 0x18, 0x5F, 0x4D, 0x87, 0x37, 0x90, 0x7F, 0xCC, 0x5A, 0x09, 0x36, 0x26, 0x21}
+var falzarGigaPalette = []uint8{0x00, 0x00, 0xDE, 0x7B, 0x74, 0x77, 0xCC, 0x49, 0xEB, 0x44, 0x07, 0x77, 0x43, 0x6E, 0x82, 0x55, 0xC1, 0x40, 0x3E, 0x1B, 0x59, 0x26, 0xB4, 0x09, 0xDD, 0x76, 0x7B, 0x55, 0x39, 0x20, 0x05, 0x18}
+var dblBeastPalette = []uint8{0x7F, 0x7D, 0x9F, 0x13, 0x5E, 0x22, 0x1F, 0x0D, 0xB1, 0x00, 0xD0, 0x41, 0x0D, 0x3D, 0x30, 0x13, 0x99, 0x61, 0xFF, 0x77, 0xA8, 0x4E, 0xA8, 0x39, 0x03, 0x21, 0xF9, 0x5A, 0x30, 0x5F, 0x61, 0x0C}
+
+func knownRAMPalettes(romID string) map[uint32][]uint8 {
+	switch romID {
+	case "BR5J":
+		return map[uint32][]uint8{0x02000b10: gregarGigaPalette, 0x02000af0: dblBeastPalette}
+	case "BR6J":
+		return map[uint32][]uint8{0x02000b10: falzarGigaPalette, 0x02000af0: dblBeastPalette}
+	case "BR6E", "BR6P", "BR5E", "BR5P":
+		return map[uint32][]uint8{0x02000af0: dblBeastPalette}
+	}
+	return nil
+}
+
+func isRAMPointer(ptr uint32) bool {
+	return ptr&0xFF000000 == 0x02000000
+}
+
+func isROMPointer(ptr uint32) bool {
+	return ptr&0xFE000000 == 0x08000000
+}
+
+func looksLikePalette(rom []byte, offset int64) bool {
+	if offset < 0 || offset+16*2 > int64(len(rom)) {
+		return false
+	}
+
+	raw := rom[offset : offset+16*2]
+	allSame := true
+	for i := 0; i < 16; i++ {
+		c := binary.LittleEndian.Uint16(raw[i*2:])
+		if c&0x8000 != 0 {
+			return false
+		}
+		if c != binary.LittleEndian.Uint16(raw) {
+			allSame = false
+		}
+	}
+	return !allSame
+}
+
+// literalLoads returns the offsets of the Thumb "ldr rd, [pc, #imm]" instructions that load the literal at offset.
+func literalLoads(rom []byte, offset int) []int {
+	var loads []int
+	for h := offset - 2; h >= 0 && h >= offset-0x400-4; h -= 2 {
+		instr := binary.LittleEndian.Uint16(rom[h:])
+		if instr&0xF800 != 0x4800 {
+			continue
+		}
+
+		if (h+4)&^3+int(instr&0xFF)*4 == offset {
+			loads = append(loads, h)
+		}
+	}
+	return loads
+}
+
+// loadedTogether reports whether the literals at a and b are loaded by instructions close enough to be the operands
+// of one copy.
+func loadedTogether(rom []byte, a int, b int) bool {
+	bLoads := literalLoads(rom, b)
+	for _, ha := range literalLoads(rom, a) {
+		for _, hb := range bLoads {
+			if d := ha - hb; d >= -ramPaletteLoadWindow && d <= ramPaletteLoadWindow {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FindRAMPalettes finds the ROM source of every RAM-resident palette pointer used by chipInfos.
+//
+// The games fill these palettes with a word copy whose source and destination come from the same literal pool, so
+// every aligned word equal to the RAM pointer is treated as a literal and its neighbours are searched for a ROM pointer
+// to something shaped like a 16 color palette. Both literals must be loaded by nearby pc-relative loads, as the copy's
+// operands are. The closest and most frequently seen candidate wins.
+//
+// Pointers that cannot be resolved are left out of the returned map and reported in the error. If a resolved palette
+// differs from one known for the game, ErrRAMPaletteMismatch is returned.
+func FindRAMPalettes(r io.ReadSeeker, chipInfos []ChipInfo) (RAMPalettes, error) {
+	retOffset, err := r.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return nil, fmt.Errorf("%w while remembering offset", err)
+	}
+	defer func() {
+		r.Seek(retOffset, os.SEEK_SET)
+	}()
+
+	wanted := map[uint32]map[int64]int{}
+	for _, ci := range chipInfos {
+		if isRAMPointer(ci.ChipPalettePtr) {
+			wanted[ci.ChipPalettePtr] = map[int64]int{}
+		}
+	}
+
+	if len(wanted) == 0 {
+		return RAMPalettes{}, nil
+	}
+
+	romID, err := gbarom.ReadROMID(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w while reading rom id", err)
+	}
+
+	if _, err := r.Seek(0, os.SEEK_SET); err != nil {
+		return nil, fmt.Errorf("%w while seeking to start of rom", err)
+	}
+
+	rom, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w while reading rom", err)
+	}
+
+	for i := 0; i+4 <= len(rom); i += 4 {
+		candidates, ok := wanted[binary.LittleEndian.Uint32(rom[i:])]
+		if !ok {
+			continue
+		}
+
+		for d := -ramPaletteSearchWindow; d <= ramPaletteSearchWindow; d++ {
+			j := i + d*4
+			if d == 0 || j < 0 || j+4 > len(rom) {
+				continue
+			}
+
+			ptr := binary.LittleEndian.Uint32(rom[j:])
+			if !isROMPointer(ptr) {
+				continue
+			}
+
+			offset := int64(ptr & ^uint32(0x08000000))
+			if !looksLikePalette(rom, offset) || !loadedTogether(rom, i, j) {
+				continue
+			}
+
+			dist := d
+			if dist < 0 {
+				dist = -dist
+			}
+			candidates[offset] += ramPaletteSearchWindow + 1 - dist
+		}
+	}
+
+	known := knownRAMPalettes(romID)
+
+	ramPalettes := RAMPalettes{}
+	var unresolved []uint32
+	for ptr, candidates := range wanted {
+		best := int64(-1)
+		for offset, score := range candidates {
+			if best == -1 || score > candidates[best] || score == candidates[best] && offset < best {
+				best = offset
+			}
+		}
+
+		if best == -1 {
+			unresolved = append(unresolved, ptr)
+			continue
+		}
+
+		if raw, ok := known[ptr]; ok && !bytes.Equal(rom[best:best+16*2], raw) {
+			return nil, fmt.Errorf("%w for 0x%08x at 0x%08x while finding ram palettes", ErrRAMPaletteMismatch, ptr, best)
+		}
+
+		ramPalettes[ptr] = best
+	}
+
+	if len(unresolved) > 0 {
+		sort.Slice(unresolved, func(i, j int) bool { return unresolved[i] < unresolved[j] })
+
+		ptrs := make([]string, len(unresolved))
+		for i, ptr := range unresolved {
+			ptrs[i] = fmt.Sprintf("0x%08x", ptr)
+		}
+		return ramPalettes, fmt.Errorf("%w %s while finding ram palettes", ErrUnresolvedRAMPointer, strings.Join(ptrs, ", "))
+	}
+
+	return ramPalettes, nil
+}
@@ -0,0 +1,61 @@
+package chips
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// makeRAMPaletteROM builds a ROM that copies a palette at 0x800 to 0x02000b10, with the pointers loaded from a literal
+// pool by the instructions at 0x400. Another palette-shaped pointer sits unloaded in the same pool.
+func makeRAMPaletteROM(romID string, withLoads bool) []byte {
+	rom := make([]byte, 0x1000)
+	copy(rom[0xAC:], romID)
+
+	if withLoads {
+		binary.LittleEndian.PutUint16(rom[0x400:], 0x4803) // ldr r0, [pc, #12]
+		binary.LittleEndian.PutUint16(rom[0x402:], 0x4904) // ldr r1, [pc, #16]
+	}
+	binary.LittleEndian.PutUint32(rom[0x40C:], 0x08000700)
+	binary.LittleEndian.PutUint32(rom[0x410:], 0x08000800)
+	binary.LittleEndian.PutUint32(rom[0x414:], 0x02000b10)
+	binary.LittleEndian.PutUint32(rom[0x418:], 0x08000700)
+
+	copy(rom[0x800:], falzarGigaPalette)
+	for i := 0; i < 16; i++ {
+		binary.LittleEndian.PutUint16(rom[0x700+i*2:], uint16(i))
+	}
+	return rom
+}
+
+func TestFindRAMPalettes(t *testing.T) {
+	chipInfos := []ChipInfo{{ChipPalettePtr: 0x02000b10}, {ChipPalettePtr: 0x08000800}}
+
+	ramPalettes, err := FindRAMPalettes(bytes.NewReader(makeRAMPaletteROM("BR6J", true)), chipInfos)
+	if err != nil {
+		t.Fatalf("FindRAMPalettes() = %v", err)
+	}
+	if got := ramPalettes[0x02000b10]; got != 0x800 {
+		t.Errorf("palette for 0x02000b10 = 0x%x, want 0x800", got)
+	}
+	if len(ramPalettes) != 1 {
+		t.Errorf("len(ramPalettes) = %d, want 1", len(ramPalettes))
+	}
+}
+
+func TestFindRAMPalettesUnloaded(t *testing.T) {
+	chipInfos := []ChipInfo{{ChipPalettePtr: 0x02000b10}}
+
+	if _, err := FindRAMPalettes(bytes.NewReader(makeRAMPaletteROM("BR6J", false)), chipInfos); !errors.Is(err, ErrUnresolvedRAMPointer) {
+		t.Errorf("FindRAMPalettes() = %v, want %v", err, ErrUnresolvedRAMPointer)
+	}
+}
+
+func TestFindRAMPalettesMismatch(t *testing.T) {
+	chipInfos := []ChipInfo{{ChipPalettePtr: 0x02000b10}}
+
+	if _, err := FindRAMPalettes(bytes.NewReader(makeRAMPaletteROM("BR5J", true)), chipInfos); !errors.Is(err, ErrRAMPaletteMismatch) {
+		t.Errorf("FindRAMPalettes() = %v, want %v", err, ErrRAMPaletteMismatch)
+	}
+}
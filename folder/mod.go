@@ -0,0 +1,198 @@
+package folder
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/murkland/bnrom/chips"
+)
+
+type Class int
+
+const (
+	ClassStandard Class = 0
+	ClassMega     Class = 1
+	ClassGiga     Class = 2
+)
+
+// ChipClass returns the class of a chip. It assumes the chip data is laid out as in BN6, where the library byte holds
+// the class: 0 for standard, 1 for mega and 2 for giga chips.
+func ChipClass(ci chips.ChipInfo) Class {
+	return Class(ci.Library)
+}
+
+type Chip struct {
+	ID   int
+	Code uint8
+}
+
+// NoRegular is the Folder.Regular of a folder without a regular chip.
+const NoRegular = -1
+
+type Folder struct {
+	Chips []Chip
+
+	// Regular is the index into Chips of the regular chip, or NoRegular if there is none.
+	Regular int
+}
+
+type Rules struct {
+	Size int
+
+	MaxStandardCopies int
+	MaxMegaCopies     int
+	MaxGigaCopies     int
+
+	MaxMegaChips int
+	MaxGigaChips int
+
+	// RegularMemory is the MB available to the regular chip. This depends on the save, not the ROM, so it is 0 in
+	// DefaultRules and the regular chip's MB is only checked once it is set.
+	RegularMemory int
+}
+
+var DefaultRules = Rules{
+	Size: 30,
+
+	MaxStandardCopies: 4,
+	MaxMegaCopies:     1,
+	MaxGigaCopies:     1,
+
+	MaxMegaChips: 5,
+	MaxGigaChips: 1,
+}
+
+func (r Rules) maxCopies(class Class) int {
+	switch class {
+	case ClassMega:
+		return r.MaxMegaCopies
+	case ClassGiga:
+		return r.MaxGigaCopies
+	}
+	return r.MaxStandardCopies
+}
+
+type ViolationKind int
+
+const (
+	ViolationWrongSize ViolationKind = iota
+	ViolationUnknownChip
+	ViolationInvalidCode
+	ViolationTooManyCopies
+	ViolationTooManyMegaChips
+	ViolationTooManyGigaChips
+	ViolationInvalidRegular
+	ViolationRegularTooLarge
+)
+
+type Violation struct {
+	Kind ViolationKind
+
+	// Index is the folder slot the violation is about, or -1 if it is about the whole folder.
+	Index  int
+	ChipID int
+	Code   uint8
+
+	Count int
+	Limit int
+}
+
+func (v Violation) String() string {
+	switch v.Kind {
+	case ViolationWrongSize:
+		return fmt.Sprintf("folder has %d chips, must have %d", v.Count, v.Limit)
+	case ViolationUnknownChip:
+		return fmt.Sprintf("slot %d: unknown chip %d", v.Index, v.ChipID)
+	case ViolationInvalidCode:
+		return fmt.Sprintf("slot %d: chip %d does not come in code %c", v.Index, v.ChipID, chips.CodeRune(v.Code))
+	case ViolationTooManyCopies:
+		return fmt.Sprintf("slot %d: %d copies of chip %d, at most %d allowed", v.Index, v.Count, v.ChipID, v.Limit)
+	case ViolationTooManyMegaChips:
+		return fmt.Sprintf("slot %d: %d mega chips, at most %d allowed", v.Index, v.Count, v.Limit)
+	case ViolationTooManyGigaChips:
+		return fmt.Sprintf("slot %d: %d giga chips, at most %d allowed", v.Index, v.Count, v.Limit)
+	case ViolationInvalidRegular:
+		return fmt.Sprintf("regular chip slot %d is out of range", v.Index)
+	case ViolationRegularTooLarge:
+		return fmt.Sprintf("slot %d: regular chip %d needs %d MB, only %d available", v.Index, v.ChipID, v.Count, v.Limit)
+	}
+	return fmt.Sprintf("slot %d: unknown violation %d", v.Index, v.Kind)
+}
+
+type Validator struct {
+	ChipInfos []chips.ChipInfo
+	Rules     Rules
+}
+
+func NewValidator(r io.ReadSeeker, ri chips.ROMInfo, rules Rules) (*Validator, error) {
+	chipInfos, err := chips.ReadChipInfos(r, ri)
+	if err != nil {
+		return nil, fmt.Errorf("%w while loading chip infos", err)
+	}
+
+	return &Validator{chipInfos, rules}, nil
+}
+
+// Validate checks a folder against the rules and returns every violation found, in slot order after any folder-wide
+// violations. A valid folder has no violations.
+func (v *Validator) Validate(f Folder) []Violation {
+	var violations []Violation
+
+	if len(f.Chips) != v.Rules.Size {
+		violations = append(violations, Violation{Kind: ViolationWrongSize, Index: -1, Count: len(f.Chips), Limit: v.Rules.Size})
+	}
+
+	if f.Regular != NoRegular && (f.Regular < 0 || f.Regular >= len(f.Chips)) {
+		violations = append(violations, Violation{Kind: ViolationInvalidRegular, Index: f.Regular})
+	}
+
+	copies := map[int]int{}
+	numMega := 0
+	numGiga := 0
+
+	for i, chip := range f.Chips {
+		if chip.ID < 0 || chip.ID >= len(v.ChipInfos) {
+			violations = append(violations, Violation{Kind: ViolationUnknownChip, Index: i, ChipID: chip.ID})
+			continue
+		}
+
+		ci := v.ChipInfos[chip.ID]
+
+		validCode := false
+		for _, code := range ci.Codes() {
+			if code == chip.Code {
+				validCode = true
+				break
+			}
+		}
+		if !validCode {
+			violations = append(violations, Violation{Kind: ViolationInvalidCode, Index: i, ChipID: chip.ID, Code: chip.Code})
+		}
+
+		class := ChipClass(ci)
+
+		copies[chip.ID]++
+		if limit := v.Rules.maxCopies(class); copies[chip.ID] > limit {
+			violations = append(violations, Violation{Kind: ViolationTooManyCopies, Index: i, ChipID: chip.ID, Count: copies[chip.ID], Limit: limit})
+		}
+
+		switch class {
+		case ClassMega:
+			numMega++
+			if numMega > v.Rules.MaxMegaChips {
+				violations = append(violations, Violation{Kind: ViolationTooManyMegaChips, Index: i, ChipID: chip.ID, Count: numMega, Limit: v.Rules.MaxMegaChips})
+			}
+		case ClassGiga:
+			numGiga++
+			if numGiga > v.Rules.MaxGigaChips {
+				violations = append(violations, Violation{Kind: ViolationTooManyGigaChips, Index: i, ChipID: chip.ID, Count: numGiga, Limit: v.Rules.MaxGigaChips})
+			}
+		}
+
+		if v.Rules.RegularMemory > 0 && i == f.Regular && int(ci.MB) > v.Rules.RegularMemory {
+			violations = append(violations, Violation{Kind: ViolationRegularTooLarge, Index: i, ChipID: chip.ID, Count: int(ci.MB), Limit: v.Rules.RegularMemory})
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,155 @@
+package folder
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/murkland/bnrom/chips"
+)
+
+func testValidator(rules Rules) *Validator {
+	return &Validator{
+		ChipInfos: []chips.ChipInfo{
+			{ChipCodes: 0xFFFFFF00, MB: 10},
+			{ChipCodes: 0xFFFFFF01, MB: 40, Library: uint8(ClassMega)},
+		},
+		Rules: rules,
+	}
+}
+
+func testFolder(regular int) Folder {
+	f := Folder{Regular: regular}
+	for i := 0; i < 30; i++ {
+		f.Chips = append(f.Chips, Chip{0, 0})
+	}
+	f.Chips[0] = Chip{1, 1}
+	return f
+}
+
+func TestValidateRegularMemoryUnset(t *testing.T) {
+	rules := DefaultRules
+	rules.MaxStandardCopies = 30
+
+	if violations := testValidator(rules).Validate(testFolder(0)); len(violations) != 0 {
+		t.Errorf("Validate() = %v, want none", violations)
+	}
+}
+
+func TestValidateRegularTooLarge(t *testing.T) {
+	rules := DefaultRules
+	rules.MaxStandardCopies = 30
+	rules.RegularMemory = 30
+
+	if violations := testValidator(rules).Validate(testFolder(NoRegular)); len(violations) != 0 {
+		t.Errorf("Validate() without a regular chip = %v, want none", violations)
+	}
+
+	violations := testValidator(rules).Validate(testFolder(0))
+	if len(violations) != 1 || violations[0].Kind != ViolationRegularTooLarge || violations[0].Count != 40 || violations[0].Limit != 30 {
+		t.Errorf("Validate() = %v, want regular chip too large", violations)
+	}
+}
+
+// classValidator has standard chips 0 to 7 in codes A and B, standard chip 8 in code * only, mega chips 9 to 15 and
+// giga chips 16 and 17, all in code A.
+func classValidator() *Validator {
+	var infos []chips.ChipInfo
+	for i := 0; i < 8; i++ {
+		infos = append(infos, chips.ChipInfo{ChipCodes: 0xFFFF0100})
+	}
+	infos = append(infos, chips.ChipInfo{ChipCodes: 0xFFFFFF00 | chips.CodeAsterisk})
+	for i := 0; i < 7; i++ {
+		infos = append(infos, chips.ChipInfo{ChipCodes: 0xFFFFFF00, Library: uint8(ClassMega)})
+	}
+	for i := 0; i < 2; i++ {
+		infos = append(infos, chips.ChipInfo{ChipCodes: 0xFFFFFF00, Library: uint8(ClassGiga)})
+	}
+	return &Validator{infos, DefaultRules}
+}
+
+// validFolder has 4 copies each of standard chips 0 to 6, in slots 0 to 27, and 2 copies of chip 8 in code *.
+func validFolder() Folder {
+	f := Folder{Regular: NoRegular}
+	for i := 0; i < 7; i++ {
+		for j := 0; j < 4; j++ {
+			f.Chips = append(f.Chips, Chip{i, uint8(j % 2)})
+		}
+	}
+	f.Chips = append(f.Chips, Chip{8, chips.CodeAsterisk}, Chip{8, chips.CodeAsterisk})
+	return f
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		modify func(f *Folder)
+		want   []Violation
+	}{
+		{
+			"valid",
+			func(f *Folder) {},
+			nil,
+		},
+		{
+			"wrong size",
+			func(f *Folder) { f.Chips = f.Chips[:29] },
+			[]Violation{{Kind: ViolationWrongSize, Index: -1, Count: 29, Limit: 30}},
+		},
+		{
+			"unknown chip",
+			func(f *Folder) { f.Chips[0] = Chip{99, 0}; f.Chips[1] = Chip{-1, 0} },
+			[]Violation{{Kind: ViolationUnknownChip, Index: 0, ChipID: 99}, {Kind: ViolationUnknownChip, Index: 1, ChipID: -1}},
+		},
+		{
+			"invalid code",
+			func(f *Folder) { f.Chips[0] = Chip{0, 2} },
+			[]Violation{{Kind: ViolationInvalidCode, Index: 0, ChipID: 0, Code: 2}},
+		},
+		{
+			"asterisk on a lettered chip",
+			func(f *Folder) { f.Chips[0] = Chip{0, chips.CodeAsterisk} },
+			[]Violation{{Kind: ViolationInvalidCode, Index: 0, ChipID: 0, Code: chips.CodeAsterisk}},
+		},
+		{
+			"letter on an asterisk chip",
+			func(f *Folder) { f.Chips[28] = Chip{8, 0} },
+			[]Violation{{Kind: ViolationInvalidCode, Index: 28, ChipID: 8, Code: 0}},
+		},
+		{
+			"too many copies",
+			func(f *Folder) { f.Chips[28] = Chip{0, 0} },
+			[]Violation{{Kind: ViolationTooManyCopies, Index: 28, ChipID: 0, Count: 5, Limit: 4}},
+		},
+		{
+			"too many copies of a mega chip",
+			func(f *Folder) { f.Chips[0] = Chip{9, 0}; f.Chips[1] = Chip{9, 0} },
+			[]Violation{{Kind: ViolationTooManyCopies, Index: 1, ChipID: 9, Count: 2, Limit: 1}},
+		},
+		{
+			"too many mega chips",
+			func(f *Folder) {
+				for i := 0; i < 6; i++ {
+					f.Chips[i] = Chip{9 + i, 0}
+				}
+			},
+			[]Violation{{Kind: ViolationTooManyMegaChips, Index: 5, ChipID: 14, Count: 6, Limit: 5}},
+		},
+		{
+			"too many giga chips",
+			func(f *Folder) { f.Chips[0] = Chip{16, 0}; f.Chips[1] = Chip{17, 0} },
+			[]Violation{{Kind: ViolationTooManyGigaChips, Index: 1, ChipID: 17, Count: 2, Limit: 1}},
+		},
+		{
+			"regular out of range",
+			func(f *Folder) { f.Regular = 30 },
+			[]Violation{{Kind: ViolationInvalidRegular, Index: 30}},
+		},
+	} {
+		f := validFolder()
+		tc.modify(&f)
+
+		if got := classValidator().Validate(f); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: Validate() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
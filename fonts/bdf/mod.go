@@ -5,47 +5,72 @@ import (
 	"fmt"
 	"image"
 	"io"
+	"strings"
 )
 
 type Properties struct {
-	XLFD    string
-	Size    int
-	DPI     image.Point
-	BPP     int
+	XLFD string
+
+	// Size is the point size at DPI.
+	Size int
+	DPI  image.Point
+	BPP  int
+
+	// BBox is the cell every glyph image is drawn in, relative to the origin. Min.Y is minus the descent.
 	BBox    image.Rectangle
 	Ascent  int
 	Descent int
 
+	Family string
+	Weight string
+
+	// DefaultChar is drawn for codepoints the font has no glyph for.
+	DefaultChar rune
+
 	NumGlyphs int
 }
 
 var ErrUnsupportedBPP = errors.New("bdf: unsupported bpp, must be one of 1, 2, 4, 8")
 
 func checkBPP(p Properties) error {
-	if 8%p.BPP != 0 {
+	if p.BPP <= 0 || 8%p.BPP != 0 {
 		return ErrUnsupportedBPP
 	}
 	return nil
 }
 
+// quote quotes a string property, doubling any quotes in it.
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func (p Properties) pixelSize() int {
+	return (p.Size*p.DPI.Y + 36) / 72
+}
+
+// WriteProperties writes the font header. Fonts with more than 1 bpp are written as BDF 2.3, which gives the bpp as a
+// fourth SIZE field, and others as BDF 2.1, whose SIZE has just the point size and resolution.
 func WriteProperties(w io.Writer, p Properties) error {
 	if err := checkBPP(p); err != nil {
 		return err
 	}
 
-	if _, err := fmt.Fprintf(w, "STARTFONT 2.3\n"); err != nil {
-		return err
+	version := "2.1"
+	size := fmt.Sprintf("%d %d %d", p.Size, p.DPI.X, p.DPI.Y)
+	if p.BPP != 1 {
+		version = "2.3"
+		size += fmt.Sprintf(" %d", p.BPP)
 	}
 
-	if _, err := fmt.Fprintf(w, "FONT %s\n", p.XLFD); err != nil {
+	if _, err := fmt.Fprintf(w, "STARTFONT %s\n", version); err != nil {
 		return err
 	}
 
-	if _, err := fmt.Fprintf(w, "SIZE %d %d %d %d\n", p.Size, p.DPI.X, p.DPI.Y, p.BPP); err != nil {
+	if _, err := fmt.Fprintf(w, "FONT %s\n", p.XLFD); err != nil {
 		return err
 	}
 
-	if _, err := fmt.Fprintf(w, "BITS_PER_PIXEL %d\n", p.BPP); err != nil {
+	if _, err := fmt.Fprintf(w, "SIZE %s\n", size); err != nil {
 		return err
 	}
 
@@ -53,16 +78,28 @@ func WriteProperties(w io.Writer, p Properties) error {
 		return err
 	}
 
-	if _, err := fmt.Fprintf(w, "STARTPROPERTIES 2\n"); err != nil {
-		return err
+	props := []string{
+		"FAMILY_NAME " + quote(p.Family),
+		"WEIGHT_NAME " + quote(p.Weight),
+		fmt.Sprintf("PIXEL_SIZE %d", p.pixelSize()),
+		fmt.Sprintf("POINT_SIZE %d", p.Size*10),
+		fmt.Sprintf("RESOLUTION_X %d", p.DPI.X),
+		fmt.Sprintf("RESOLUTION_Y %d", p.DPI.Y),
+		`CHARSET_REGISTRY "ISO10646"`,
+		`CHARSET_ENCODING "1"`,
+		fmt.Sprintf("DEFAULT_CHAR %d", p.DefaultChar),
+		fmt.Sprintf("FONT_ASCENT %d", p.Ascent),
+		fmt.Sprintf("FONT_DESCENT %d", p.Descent),
 	}
 
-	if _, err := fmt.Fprintf(w, "FONT_ASCENT %d\n", p.Ascent); err != nil {
+	if _, err := fmt.Fprintf(w, "STARTPROPERTIES %d\n", len(props)); err != nil {
 		return err
 	}
 
-	if _, err := fmt.Fprintf(w, "FONT_DESCENT %d\n", p.Descent); err != nil {
-		return err
+	for _, prop := range props {
+		if _, err := fmt.Fprintf(w, "%s\n", prop); err != nil {
+			return err
+		}
 	}
 
 	if _, err := fmt.Fprintf(w, "ENDPROPERTIES\n"); err != nil {
@@ -76,7 +113,23 @@ func WriteProperties(w io.Writer, p Properties) error {
 	return nil
 }
 
-func WriteGlyph(w io.Writer, p Properties, width int, codepoint rune, img *image.Alpha) error {
+// trim returns the smallest rectangle of img holding every pixel that isn't fully transparent.
+func trim(img *image.Alpha) image.Rectangle {
+	var r image.Rectangle
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			if img.AlphaAt(x, y).A == 0 {
+				continue
+			}
+			r = r.Union(image.Rect(x, y, x+1, y+1))
+		}
+	}
+	return r
+}
+
+// writeGlyph writes a glyph whose image is drawn in the font's bounding box. Its BBX is trimmed to the pixels that
+// aren't fully transparent.
+func writeGlyph(w io.Writer, p Properties, width int, codepoint rune, img *image.Alpha) error {
 	if err := checkBPP(p); err != nil {
 		return err
 	}
@@ -91,7 +144,7 @@ func WriteGlyph(w io.Writer, p Properties, width int, codepoint rune, img *image
 		return err
 	}
 
-	if _, err := fmt.Fprintf(w, "SWIDTH %d 0\n", width*1000/p.BBox.Dx()); err != nil {
+	if _, err := fmt.Fprintf(w, "SWIDTH %d 0\n", width*1000*72/(p.Size*p.DPI.X)); err != nil {
 		return err
 	}
 
@@ -99,7 +152,14 @@ func WriteGlyph(w io.Writer, p Properties, width int, codepoint rune, img *image
 		return err
 	}
 
-	if _, err := fmt.Fprintf(w, "BBX %d %d %d %d\n", img.Rect.Dx(), img.Rect.Dy(), p.BBox.Min.X, p.BBox.Min.Y); err != nil {
+	r := trim(img)
+	xoff := p.BBox.Min.X + r.Min.X - img.Rect.Min.X
+	yoff := p.BBox.Min.Y + img.Rect.Max.Y - r.Max.Y
+	if r.Empty() {
+		xoff, yoff = 0, 0
+	}
+
+	if _, err := fmt.Fprintf(w, "BBX %d %d %d %d\n", r.Dx(), r.Dy(), xoff, yoff); err != nil {
 		return err
 	}
 
@@ -107,11 +167,10 @@ func WriteGlyph(w io.Writer, p Properties, width int, codepoint rune, img *image
 		return err
 	}
 
-	for j := 0; j < img.Bounds().Dy(); j++ {
-		row := img.Pix[j*img.Bounds().Dx() : (j+1)*img.Bounds().Dx()]
-
-		if r := len(row) % ppb; r != 0 {
-			row = append(row, make([]uint8, ppb-r)...)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := make([]uint8, (r.Dx()+ppb-1)/ppb*ppb)
+		for x := r.Min.X; x < r.Max.X; x++ {
+			row[x-r.Min.X] = img.AlphaAt(x, y).A
 		}
 
 		for j := 0; j < len(row); j += ppb {
@@ -119,9 +178,14 @@ func WriteGlyph(w io.Writer, p Properties, width int, codepoint rune, img *image
 			for i, b := range row[j : j+ppb] {
 				mask |= uint8((uint32(b) * ((1 << p.BPP) - 1) / 0xff)) << ((ppb - i - 1) * p.BPP)
 			}
-			fmt.Fprintf(w, "%02X", mask)
+			if _, err := fmt.Fprintf(w, "%02X", mask); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "\n"); err != nil {
+			return err
 		}
-		fmt.Fprintf(w, "\n")
 	}
 
 	if _, err := fmt.Fprintf(w, "ENDCHAR\n"); err != nil {
@@ -138,3 +202,35 @@ func WriteTrailer(w io.Writer) error {
 
 	return nil
 }
+
+var ErrDuplicateEncoding = errors.New("bdf: duplicate encoding")
+
+// Writer writes a BDF font, refusing glyphs whose codepoint has already been written: most BDF readers keep only one
+// glyph per encoding, so a duplicate would silently lose a glyph.
+type Writer struct {
+	w         io.Writer
+	p         Properties
+	encodings map[rune]struct{}
+}
+
+func NewWriter(w io.Writer, p Properties) (*Writer, error) {
+	if err := WriteProperties(w, p); err != nil {
+		return nil, err
+	}
+	return &Writer{w, p, map[rune]struct{}{}}, nil
+}
+
+// WriteGlyph writes a glyph whose image is drawn in the font's bounding box. Its BBX is trimmed to the pixels that
+// aren't fully transparent.
+func (w *Writer) WriteGlyph(width int, codepoint rune, img *image.Alpha) error {
+	if _, ok := w.encodings[codepoint]; ok {
+		return fmt.Errorf("%w: U+%04X", ErrDuplicateEncoding, codepoint)
+	}
+	w.encodings[codepoint] = struct{}{}
+
+	return writeGlyph(w.w, w.p, width, codepoint, img)
+}
+
+func (w *Writer) Close() error {
+	return WriteTrailer(w.w)
+}
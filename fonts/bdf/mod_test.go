@@ -0,0 +1,124 @@
+package bdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testGlyph struct {
+	width     int
+	codepoint rune
+	img       *image.Alpha
+}
+
+// randomGlyph draws a glyph in a cell the size of bbox, using only alpha values that survive quantizing to bpp.
+func randomGlyph(rng *rand.Rand, bbox image.Rectangle, bpp int, codepoint rune) testGlyph {
+	max := 1<<bpp - 1
+
+	img := image.NewAlpha(image.Rect(0, 0, bbox.Dx(), bbox.Dy()))
+	for y := 2; y < bbox.Dy()-1; y++ {
+		for x := 1; x < bbox.Dx()-2; x++ {
+			img.Pix[img.PixOffset(x, y)] = uint8(rng.Intn(max+1) * 0xff / max)
+		}
+	}
+	return testGlyph{rng.Intn(bbox.Dx()) + 1, codepoint, img}
+}
+
+func TestRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for _, bpp := range []int{1, 2, 4, 8} {
+		p := Properties{
+			XLFD: "-Test-Test Font-Medium-R-Normal--16-120-96-96-P-80-ISO10646-1",
+			Size: 12,
+			DPI:  image.Point{96, 96},
+			BPP:  bpp,
+
+			BBox:    image.Rect(-1, -3, 9, 13),
+			Ascent:  13,
+			Descent: 3,
+
+			Family: `Test "Font"`,
+			Weight: "Medium",
+
+			DefaultChar: '?',
+		}
+
+		glyphs := []testGlyph{
+			randomGlyph(rng, p.BBox, bpp, '?'),
+			randomGlyph(rng, p.BBox, bpp, 'A'),
+			{4, ' ', image.NewAlpha(image.Rect(0, 0, p.BBox.Dx(), p.BBox.Dy()))},
+			randomGlyph(rng, p.BBox, bpp, 'あ'),
+		}
+		p.NumGlyphs = len(glyphs)
+
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, p)
+		if err != nil {
+			t.Fatalf("%d bpp: NewWriter() = %v", bpp, err)
+		}
+		for _, g := range glyphs {
+			if err := w.WriteGlyph(g.width, g.codepoint, g.img); err != nil {
+				t.Fatalf("%d bpp: WriteGlyph(%q) = %v", bpp, g.codepoint, err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%d bpp: Close() = %v", bpp, err)
+		}
+
+		// BDF 2.1 gives the point size and resolution, and BDF 2.3 adds the bpp for grayscale fonts.
+		wantSize := "SIZE 12 96 96"
+		if bpp != 1 {
+			wantSize += fmt.Sprintf(" %d", bpp)
+		}
+		if lines := strings.Split(buf.String(), "\n"); len(lines) < 3 || lines[2] != wantSize {
+			t.Errorf("%d bpp: SIZE line is not %q:\n%s", bpp, wantSize, buf.String())
+		}
+
+		f, err := Read(&buf)
+		if err != nil {
+			t.Fatalf("%d bpp: Read() = %v", bpp, err)
+		}
+
+		if !reflect.DeepEqual(f.Properties, p) {
+			t.Errorf("%d bpp: Read() properties = %+v, want %+v", bpp, f.Properties, p)
+		}
+
+		if len(f.Glyphs) != len(glyphs) {
+			t.Fatalf("%d bpp: Read() has %d glyphs, want %d", bpp, len(f.Glyphs), len(glyphs))
+		}
+
+		for i, g := range f.Glyphs {
+			want := glyphs[i]
+			if g.Encoding != want.codepoint || g.Width != want.width {
+				t.Errorf("%d bpp: glyph %d is %q with width %d, want %q with width %d", bpp, i, g.Encoding, g.Width, want.codepoint, want.width)
+			}
+			if cell := f.Cell(g); !bytes.Equal(cell.Pix, want.img.Pix) {
+				t.Errorf("%d bpp: glyph %q does not draw the same as it was written", bpp, want.codepoint)
+			}
+		}
+	}
+}
+
+func TestWriterDuplicateEncoding(t *testing.T) {
+	p := Properties{Size: 12, DPI: image.Point{72, 72}, BPP: 1, BBox: image.Rect(0, 0, 8, 8), NumGlyphs: 2}
+
+	w, err := NewWriter(&bytes.Buffer{}, p)
+	if err != nil {
+		t.Fatalf("NewWriter() = %v", err)
+	}
+
+	img := image.NewAlpha(image.Rect(0, 0, 8, 8))
+	if err := w.WriteGlyph(8, 'A', img); err != nil {
+		t.Fatalf("WriteGlyph('A') = %v", err)
+	}
+	if err := w.WriteGlyph(8, 'A', img); !errors.Is(err, ErrDuplicateEncoding) {
+		t.Errorf("second WriteGlyph('A') = %v, want %v", err, ErrDuplicateEncoding)
+	}
+}
@@ -0,0 +1,228 @@
+package bdf
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrSyntax = errors.New("bdf: syntax error")
+
+type Glyph struct {
+	Encoding rune
+
+	// Width is how far to advance after drawing the glyph.
+	Width int
+
+	// BBox is the glyph's bounding box as given by BBX: Min is the offset from the origin and the size is the size of
+	// Image.
+	BBox image.Rectangle
+
+	Image *image.Alpha
+}
+
+type Font struct {
+	Properties
+	Glyphs []Glyph
+}
+
+// Cell returns a glyph drawn into an image the size of the font's bounding box, the way Writer.WriteGlyph lays it out.
+func (f *Font) Cell(g Glyph) *image.Alpha {
+	cell := image.NewAlpha(image.Rect(0, 0, f.BBox.Dx(), f.BBox.Dy()))
+
+	x := g.BBox.Min.X - f.BBox.Min.X
+	y := (f.BBox.Dy() + f.BBox.Min.Y) - (g.BBox.Dy() + g.BBox.Min.Y)
+	draw.Draw(cell, image.Rect(x, y, x+g.BBox.Dx(), y+g.BBox.Dy()), g.Image, image.Point{}, draw.Src)
+
+	return cell
+}
+
+func atois(fields []string, n int) ([]int, error) {
+	if len(fields) < n {
+		return nil, fmt.Errorf("%w: expected %d values, got %d", ErrSyntax, n, len(fields))
+	}
+
+	vals := make([]int, n)
+	for i := range vals {
+		v, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad number %q", ErrSyntax, fields[i])
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+func unquote(fields []string) (string, error) {
+	s := strings.Join(fields, " ")
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("%w: bad string %s", ErrSyntax, s)
+	}
+	return strings.ReplaceAll(s[1:len(s)-1], `""`, `"`), nil
+}
+
+func readBitmapRow(line string, bpp int, row []uint8) error {
+	buf, err := hex.DecodeString(line)
+	if err != nil {
+		return fmt.Errorf("%w: bad bitmap row %q", ErrSyntax, line)
+	}
+
+	ppb := 8 / bpp
+	if len(buf)*ppb < len(row) {
+		return fmt.Errorf("%w: bitmap row %q too short", ErrSyntax, line)
+	}
+
+	max := uint32(1)<<bpp - 1
+	for i := range row {
+		v := uint32(buf[i/ppb]>>((ppb-i%ppb-1)*bpp)) & max
+		row[i] = uint8(v * 0xff / max)
+	}
+	return nil
+}
+
+// Read parses a BDF font as written by Writer. Properties other than the ones in Properties are ignored, as are SWIDTH
+// and glyph names.
+func Read(r io.Reader) (*Font, error) {
+	f := &Font{Properties: Properties{BPP: 1}}
+
+	s := bufio.NewScanner(r)
+	lineno := 0
+	next := func() (string, []string, bool) {
+		for s.Scan() {
+			lineno++
+			fields := strings.Fields(s.Text())
+			if len(fields) == 0 || fields[0] == "COMMENT" {
+				continue
+			}
+			return fields[0], fields[1:], true
+		}
+		return "", nil, false
+	}
+
+	var g *Glyph
+	for {
+		keyword, fields, ok := next()
+		if !ok {
+			break
+		}
+
+		var err error
+		switch keyword {
+		case "FONT":
+			f.XLFD = strings.Join(fields, " ")
+		case "SIZE":
+			var vals []int
+			if vals, err = atois(fields, 3); err == nil {
+				f.Size = vals[0]
+				f.DPI = image.Point{vals[1], vals[2]}
+				if len(fields) >= 4 {
+					var bpp []int
+					if bpp, err = atois(fields[3:], 1); err == nil {
+						f.BPP = bpp[0]
+					}
+				}
+			}
+		case "BITS_PER_PIXEL":
+			var vals []int
+			if vals, err = atois(fields, 1); err == nil {
+				f.BPP = vals[0]
+			}
+		case "FONTBOUNDINGBOX":
+			var vals []int
+			if vals, err = atois(fields, 4); err == nil {
+				f.BBox = image.Rect(vals[2], vals[3], vals[2]+vals[0], vals[3]+vals[1])
+			}
+		case "FAMILY_NAME":
+			f.Family, err = unquote(fields)
+		case "WEIGHT_NAME":
+			f.Weight, err = unquote(fields)
+		case "DEFAULT_CHAR":
+			var vals []int
+			if vals, err = atois(fields, 1); err == nil {
+				f.DefaultChar = rune(vals[0])
+			}
+		case "FONT_ASCENT":
+			var vals []int
+			if vals, err = atois(fields, 1); err == nil {
+				f.Ascent = vals[0]
+			}
+		case "FONT_DESCENT":
+			var vals []int
+			if vals, err = atois(fields, 1); err == nil {
+				f.Descent = vals[0]
+			}
+		case "CHARS":
+			var vals []int
+			if vals, err = atois(fields, 1); err == nil {
+				f.NumGlyphs = vals[0]
+			}
+		case "STARTCHAR":
+			if err = checkBPP(f.Properties); err != nil {
+				break
+			}
+			g = &Glyph{Encoding: -1}
+		case "ENCODING":
+			var vals []int
+			if g != nil {
+				if vals, err = atois(fields, 1); err == nil {
+					g.Encoding = rune(vals[0])
+				}
+			}
+		case "DWIDTH":
+			var vals []int
+			if g != nil {
+				if vals, err = atois(fields, 1); err == nil {
+					g.Width = vals[0]
+				}
+			}
+		case "BBX":
+			var vals []int
+			if g != nil {
+				if vals, err = atois(fields, 4); err == nil {
+					g.BBox = image.Rect(vals[2], vals[3], vals[2]+vals[0], vals[3]+vals[1])
+				}
+			}
+		case "BITMAP":
+			if g == nil {
+				err = fmt.Errorf("%w: BITMAP outside of a glyph", ErrSyntax)
+				break
+			}
+
+			g.Image = image.NewAlpha(image.Rect(0, 0, g.BBox.Dx(), g.BBox.Dy()))
+			for j := 0; j < g.BBox.Dy() && err == nil; j++ {
+				line, _, ok := next()
+				if !ok {
+					err = fmt.Errorf("%w: bitmap ends early", ErrSyntax)
+					break
+				}
+				err = readBitmapRow(line, f.BPP, g.Image.Pix[j*g.Image.Stride:(j+1)*g.Image.Stride])
+			}
+		case "ENDCHAR":
+			if g == nil {
+				err = fmt.Errorf("%w: ENDCHAR outside of a glyph", ErrSyntax)
+				break
+			}
+			if g.Image == nil {
+				g.Image = image.NewAlpha(image.Rect(0, 0, g.BBox.Dx(), g.BBox.Dy()))
+			}
+			f.Glyphs = append(f.Glyphs, *g)
+			g = nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w on line %d", err, lineno)
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
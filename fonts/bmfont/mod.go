@@ -0,0 +1,107 @@
+package bmfont
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+type Glyph struct {
+	Codepoint rune
+
+	// XAdvance is how far to advance after drawing the glyph.
+	XAdvance int
+
+	Image *image.Alpha
+}
+
+type Info struct {
+	Face string
+	Size int
+
+	LineHeight int
+
+	// Base is the distance from the top of a line to the baseline.
+	Base int
+}
+
+// PageWidth is the width of the glyph page. Its height is the next power of two that fits every glyph.
+const PageWidth = 256
+
+// padding is the gap left between glyphs on the page so filtering doesn't bleed one glyph into the next.
+const padding = 1
+
+// pack lays glyphs out on the page in rows, returning where each one goes and the height of the page.
+func pack(glyphs []Glyph) ([]image.Rectangle, int) {
+	rects := make([]image.Rectangle, len(glyphs))
+
+	x, y, rowHeight := 0, 0, 0
+	for i, g := range glyphs {
+		size := g.Image.Rect.Size()
+		if x+size.X > PageWidth {
+			x = 0
+			y += rowHeight + padding
+			rowHeight = 0
+		}
+
+		rects[i] = image.Rectangle{image.Point{x, y}, image.Point{x, y}.Add(size)}
+
+		x += size.X + padding
+		if size.Y > rowHeight {
+			rowHeight = size.Y
+		}
+	}
+
+	height := 1
+	for height < y+rowHeight {
+		height *= 2
+	}
+
+	return rects, height
+}
+
+// Write writes a font as a BMFont text descriptor to fnt and its single glyph page as a PNG to page. pageFn is the name
+// the descriptor refers to the page by. Glyphs are drawn in white with their coverage in the alpha channel.
+func Write(fnt io.Writer, page io.Writer, pageFn string, info Info, glyphs []Glyph) error {
+	rects, height := pack(glyphs)
+
+	img := image.NewNRGBA(image.Rect(0, 0, PageWidth, height))
+	for i, g := range glyphs {
+		for y := 0; y < g.Image.Rect.Dy(); y++ {
+			for x := 0; x < g.Image.Rect.Dx(); x++ {
+				a := g.Image.AlphaAt(g.Image.Rect.Min.X+x, g.Image.Rect.Min.Y+y).A
+				img.SetNRGBA(rects[i].Min.X+x, rects[i].Min.Y+y, color.NRGBA{0xff, 0xff, 0xff, a})
+			}
+		}
+	}
+
+	if err := png.Encode(page, img); err != nil {
+		return fmt.Errorf("%w while writing page", err)
+	}
+
+	if _, err := fmt.Fprintf(fnt, "info face=%q size=%d bold=0 italic=0 charset=\"\" unicode=1 stretchH=100 smooth=0 aa=1 padding=0,0,0,0 spacing=%d,%d outline=0\n", info.Face, info.Size, padding, padding); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(fnt, "common lineHeight=%d base=%d scaleW=%d scaleH=%d pages=1 packed=0 alphaChnl=0 redChnl=4 greenChnl=4 blueChnl=4\n", info.LineHeight, info.Base, PageWidth, height); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(fnt, "page id=0 file=%q\n", pageFn); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(fnt, "chars count=%d\n", len(glyphs)); err != nil {
+		return err
+	}
+
+	for i, g := range glyphs {
+		if _, err := fmt.Fprintf(fnt, "char id=%d x=%d y=%d width=%d height=%d xoffset=0 yoffset=0 xadvance=%d page=0 chnl=15\n", g.Codepoint, rects[i].Min.X, rects[i].Min.Y, rects[i].Dx(), rects[i].Dy(), g.XAdvance); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
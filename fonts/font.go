@@ -0,0 +1,156 @@
+package fonts
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+)
+
+// NumGlyphs is the number of glyphs in the tall and tall2 fonts, one per charmap entry.
+const NumGlyphs = 448
+
+type Font struct {
+	Glyphs []*image.Alpha
+
+	// Widths is how far to advance after drawing each glyph.
+	Widths []int
+
+	Charmap []rune
+
+	LineHeight int
+}
+
+var ErrUnsupportedFormat = errors.New("fonts: unsupported glyph format")
+
+// glyphCharmap returns a charmap covering every glyph, giving placeholders to glyphs past the end of charmap.
+func glyphCharmap(charmap []rune) []rune {
+	out := make([]rune, NumGlyphs)
+	for i := range out {
+		if i < len(charmap) {
+			out[i] = charmap[i]
+		} else {
+			out[i] = Placeholder(i)
+		}
+	}
+	return out
+}
+
+// ReadTinyFont reads the tiny digit font, whose glyphs are pointed to one by one from a table at ri.TinyOffset.
+func ReadTinyFont(r io.ReadSeeker, ri ROMInfo) (*Font, error) {
+	const n = 10
+
+	if _, err := r.Seek(ri.TinyOffset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%w while seeking to tiny font pointer", err)
+	}
+
+	offsets := make([]int64, n)
+	for i := range offsets {
+		var offset uint32
+		if err := binary.Read(r, binary.LittleEndian, &offset); err != nil {
+			return nil, fmt.Errorf("%w while reading offset to tiny font glyph %d", err, i)
+		}
+		offsets[i] = int64(offset &^ 0x08000000)
+	}
+
+	f := &Font{
+		Glyphs:     make([]*image.Alpha, n),
+		Widths:     make([]int, n),
+		Charmap:    []rune("0123456789"),
+		LineHeight: 16,
+	}
+
+	for i, offset := range offsets {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("%w while seeking to tiny font glyph %d", err, i)
+		}
+
+		glyph, err := ReadGlyph(r, 5)
+		if err != nil {
+			return nil, fmt.Errorf("%w while reading tiny font glyph %d", err, i)
+		}
+
+		f.Glyphs[i] = glyph
+		f.Widths[i] = 8
+	}
+
+	return f, nil
+}
+
+func ReadTallFont(r io.ReadSeeker, ri ROMInfo) (*Font, error) {
+	if ri.TallFormat != GlyphFormat8x16 {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if _, err := r.Seek(ri.TallOffset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%w while seeking to tall font pointer", err)
+	}
+
+	var offset uint32
+	if err := binary.Read(r, binary.LittleEndian, &offset); err != nil {
+		return nil, fmt.Errorf("%w while reading offset to tall font", err)
+	}
+
+	if _, err := r.Seek(int64(offset&^0x08000000), io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%w while seeking to tall font", err)
+	}
+
+	f := &Font{
+		Glyphs:     make([]*image.Alpha, NumGlyphs),
+		Widths:     make([]int, NumGlyphs),
+		Charmap:    glyphCharmap(ri.Charmap),
+		LineHeight: 16,
+	}
+
+	for i := 0; i < NumGlyphs; i++ {
+		glyph, err := ReadGlyph(r, 1)
+		if err != nil {
+			return nil, fmt.Errorf("%w while reading tall font glyph %d", err, i)
+		}
+
+		f.Glyphs[i] = glyph
+		f.Widths[i] = 8
+	}
+
+	return f, nil
+}
+
+func ReadTall2Font(r io.ReadSeeker, ri ROMInfo) (*Font, error) {
+	if ri.Tall2Format != GlyphFormat16x12 {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if _, err := r.Seek(ri.Tall2MetricsOffset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%w while seeking to tall2 font metrics", err)
+	}
+
+	widths, err := ReadMetrics(r, NumGlyphs)
+	if err != nil {
+		return nil, fmt.Errorf("%w while reading tall2 font metrics", err)
+	}
+
+	// The first glyph is always blank and isn't stored.
+	if _, err := r.Seek(ri.Tall2Offset+0x60, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%w while seeking to tall2 font", err)
+	}
+
+	f := &Font{
+		Glyphs:     make([]*image.Alpha, NumGlyphs),
+		Widths:     widths,
+		Charmap:    glyphCharmap(ri.Charmap),
+		LineHeight: 12,
+	}
+
+	f.Glyphs[0] = image.NewAlpha(image.Rect(0, 0, 16, 12))
+	for i := 1; i < NumGlyphs; i++ {
+		glyph, err := Read16x12Glyph(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w while reading tall2 font glyph %d", err, i)
+		}
+
+		f.Glyphs[i] = glyph
+	}
+
+	return f, nil
+}
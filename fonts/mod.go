@@ -4,60 +4,72 @@ import (
 	"image"
 	"image/color"
 	"io"
+	"unicode/utf8"
 
 	"github.com/murkland/bnrom/sprites"
 )
 
+// GlyphFormat is how a font's glyphs are stored.
+type GlyphFormat int
+
+const (
+	// GlyphFormatUnknown is the zero value, which no located font has.
+	GlyphFormatUnknown GlyphFormat = iota
+
+	// GlyphFormat8x16 glyphs are two 8x8 tiles stacked vertically, all the same width.
+	GlyphFormat8x16
+
+	// GlyphFormat16x12 glyphs are 16x12 with a shadow color, and have their widths in a separate metrics table.
+	GlyphFormat16x12
+)
+
 type ROMInfo struct {
 	TinyOffset int64
+
 	TallOffset int64
+	TallFormat GlyphFormat
 
 	Tall2Offset        int64
 	Tall2MetricsOffset int64
+	Tall2Format        GlyphFormat
 
 	Charmap []rune
 }
 
-func tall2Offset(gameTitle string) int64 {
-	switch gameTitle {
-	case "MEGAMAN6_FXX":
-		return 0x006ACD60
-	case "MEGAMAN6_GXX":
-		return 0x006AACAC
-	case "ROCKEXE6_RXX":
-		return 0x006CBE80
-	case "ROCKEXE6_GXX":
-		return 0x006C9DB4
-	}
-	return 0
+// PlaceholderBase is where the Private Use Area codepoints given to charmap slots with no known character start. Slot i
+// gets PlaceholderBase+i, so every glyph keeps a distinct codepoint.
+const PlaceholderBase = 0xE000
+
+func Placeholder(i int) rune {
+	return PlaceholderBase + rune(i)
 }
 
-func tall2MetricsOffset(gameTitle string) int64 {
-	switch gameTitle {
-	case "MEGAMAN6_FXX":
-		return 0x00043CA4
-	case "MEGAMAN6_GXX":
-		return 0x00043C74
-	case "ROCKEXE6_RXX":
-		return 0x00044EEC
-	case "ROCKEXE6_GXX":
-		return 0x00044EBC
+func makeCharmap(s string) []rune {
+	charmap := []rune(s)
+	for i, c := range charmap {
+		if c == utf8.RuneError {
+			charmap[i] = Placeholder(i)
+		}
 	}
-	return 0
+	return charmap
 }
 
-func FindROMInfo(romID string, gameTitle string) *ROMInfo {
+var (
+	charmapEN = makeCharmap(" 0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ*abcdefghijklmnopqrstuvwxyz�����ウアイオエケコカクキセサソシステトツタチネノヌナニヒヘホハフミマメムモヤヨユロルリレラン熱斗ワヲギガゲゴグゾジゼズザデドヅダヂベビボバブピパペプポゥァィォェュヴッョャ-×=:%?+█�ー!&,゜.・;'\"~/()「」�_�����あいけくきこかせそすさしつとてたちねのなぬにへふほはひめむみもまゆよやるらりろれ�んをわ研げぐごがぎぜずじぞざでどづだぢべばびぼぶぽぷぴぺぱぅぁぃぉぇゅょっゃ容量全木�無現実◯✗緑道不止彩起父集院一二三四五六七八陽十百千万脳上下左右手来日目月獣各人入出山口光電気綾科次名前学校省祐室世界高朗枚野悪路闇大小中自分間系花問究門城王兄化葉行街屋水見終新桜先生長今了点井子言太属風会性持時勝赤代年火改計画職体波回外地員正造値合戦川秋原町晴用金郎作数方社攻撃力同武何発少教以白早暮面組後文字本階明才者向犬々ヶ連射舟戸切土炎伊夫鉄国男天老師堀杉士悟森霧麻剛垣")
+	charmapJP = makeCharmap(" 0123456789ウアイオエケコカクキセサソシステトツタチネノヌナニヒヘホハフミマメムモヤヨユロルリレラン熱斗ワヲギガゲゴグゾジゼズザデドヅダヂベビボバブピパペプポゥァィォェュヴッョャABCDEFGHIJKLMNOPQRSTUVWXYZ*-×=:%?+■�ー!��&、゜.・;’\"~/()「」����_�周えおうあいけくきこかせそすさしつとてたちねのなぬにへふほはひめむみもまゆよやるらりろれ�んをわ研げぐごがぎぜずじぞざでどづだぢべばびぼぶぽぷぴぺぱぅぁぃぉぇゅょっゃabcdefghijklmnopqrstuvwxyz容量全木�無現実◯✗緑道不止彩起父集院一二三四五六七八陽十百千万脳上下左右手来日目月獣各人入出山口光電気綾科次名前学校省祐室世界高朗枚野悪路闇大小中自分間系花問究門城王兄化葉行街屋水見終新桜先生長今了点井子言太属風会性持時勝赤代年火改計画職体波回外地員正造値合戦川秋原町晴用金郎作数方社攻撃力同武何発少教以白早暮面組後文字本階明才者向犬々ヶ連射舟戸切土炎伊夫鉄国男天老師堀杉士悟森霧麻剛垣")
+)
+
+// FindROMInfo returns where a game keeps its fonts, or nil if they haven't been located for it.
+func FindROMInfo(romID string) *ROMInfo {
 	switch romID {
-	case "BR6E", "BR6P", "BR5E", "BR5P":
-		return &ROMInfo{
-			0x0001D854, 0x0001C824, tall2Offset(gameTitle), tall2MetricsOffset(gameTitle),
-			[]rune(" 0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ*abcdefghijklmnopqrstuvwxyz�����ウアイオエケコカクキセサソシステトツタチネノヌナニヒヘホハフミマメムモヤヨユロルリレラン熱斗ワヲギガゲゴグゾジゼズザデドヅダヂベビボバブピパペプポゥァィォェュヴッョャ-×=:%?+█�ー!&,゜.・;'\"~/()「」�_�����あいけくきこかせそすさしつとてたちねのなぬにへふほはひめむみもまゆよやるらりろれ�んをわ研げぐごがぎぜずじぞざでどづだぢべばびぼぶぽぷぴぺぱぅぁぃぉぇゅょっゃ容量全木�無現実◯✗緑道不止彩起父集院一二三四五六七八陽十百千万脳上下左右手来日目月獣各人入出山口光電気綾科次名前学校省祐室世界高朗枚野悪路闇大小中自分間系花問究門城王兄化葉行街屋水見終新桜先生長今了点井子言太属風会性持時勝赤代年火改計画職体波回外地員正造値合戦川秋原町晴用金郎作数方社攻撃力同武何発少教以白早暮面組後文字本階明才者向犬々ヶ連射舟戸切土炎伊夫鉄国男天老師堀杉士悟森霧麻剛垣"),
-		}
-	case "BR6J", "BR5J":
-		return &ROMInfo{
-			0x0001DC78, 0x0001CC48, tall2Offset(gameTitle), tall2MetricsOffset(gameTitle),
-			[]rune(" 0123456789ウアイオエケコカクキセサソシステトツタチネノヌナニヒヘホハフミマメムモヤヨユロルリレラン熱斗ワヲギガゲゴグゾジゼズザデドヅダヂベビボバブピパペプポゥァィォェュヴッョャABCDEFGHIJKLMNOPQRSTUVWXYZ*-×=:%?+■�ー!��&、゜.・;’\"~/()「」����_�周えおうあいけくきこかせそすさしつとてたちねのなぬにへふほはひめむみもまゆよやるらりろれ�んをわ研げぐごがぎぜずじぞざでどづだぢべばびぼぶぽぷぴぺぱぅぁぃぉぇゅょっゃabcdefghijklmnopqrstuvwxyz容量全木�無現実◯✗緑道不止彩起父集院一二三四五六七八陽十百千万脳上下左右手来日目月獣各人入出山口光電気綾科次名前学校省祐室世界高朗枚野悪路闇大小中自分間系花問究門城王兄化葉行街屋水見終新桜先生長今了点井子言太属風会性持時勝赤代年火改計画職体波回外地員正造値合戦川秋原町晴用金郎作数方社攻撃力同武何発少教以白早暮面組後文字本階明才者向犬々ヶ連射舟戸切土炎伊夫鉄国男天老師堀杉士悟森霧麻剛垣"),
-		}
+	case "BR6E", "BR6P":
+		return &ROMInfo{0x0001D854, 0x0001C824, GlyphFormat8x16, 0x006ACD60, 0x00043CA4, GlyphFormat16x12, charmapEN}
+	case "BR5E", "BR5P":
+		return &ROMInfo{0x0001D854, 0x0001C824, GlyphFormat8x16, 0x006AACAC, 0x00043C74, GlyphFormat16x12, charmapEN}
+	case "BR6J":
+		return &ROMInfo{0x0001DC78, 0x0001CC48, GlyphFormat8x16, 0x006CBE80, 0x00044EEC, GlyphFormat16x12, charmapJP}
+	case "BR5J":
+		return &ROMInfo{0x0001DC78, 0x0001CC48, GlyphFormat8x16, 0x006C9DB4, 0x00044EBC, GlyphFormat16x12, charmapJP}
 	}
 	return nil
 }
@@ -0,0 +1,175 @@
+package fonts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/murkland/bnrom/fonts/bdf"
+	"github.com/murkland/bnrom/sprites"
+)
+
+var ErrUnpatchable = errors.New("fonts: unpatchable")
+
+// WriteGlyph writes an 8x16 glyph as two 4bpp tiles, as read by ReadGlyph. Pixels at least half opaque are written as
+// opaqueColor, the rest as 0.
+func WriteGlyph(w io.Writer, glyph *image.Alpha, opaqueColor uint8) error {
+	for o := 0; o < 2; o++ {
+		tile := image.NewPaletted(image.Rect(0, 0, 8, 8), nil)
+		for j := 0; j < 8; j++ {
+			for i := 0; i < 8; i++ {
+				if glyph.AlphaAt(glyph.Rect.Min.X+i, glyph.Rect.Min.Y+j+o*8).A >= 0x80 {
+					tile.Pix[j*8+i] = opaqueColor
+				}
+			}
+		}
+
+		if err := sprites.WriteTile(w, tile); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Write16x12Glyph writes a 16x12 glyph, as read by Read16x12Glyph. Pixels at least half opaque are written as 1, other
+// pixels that aren't fully transparent as the shadow color 3.
+func Write16x12Glyph(w io.Writer, glyph *image.Alpha) error {
+	tile := image.NewPaletted(image.Rect(0, 0, 16, 12), nil)
+	for j := 0; j < tile.Rect.Dy(); j++ {
+		for i := 0; i < tile.Rect.Dx(); i++ {
+			a := glyph.AlphaAt(glyph.Rect.Min.X+i, glyph.Rect.Min.Y+j).A
+			switch {
+			case a >= 0x80:
+				tile.Pix[j*tile.Rect.Dx()+i] = 1
+			case a > 0:
+				tile.Pix[j*tile.Rect.Dx()+i] = 3
+			}
+		}
+	}
+
+	return sprites.WriteTile(w, tile)
+}
+
+func WriteMetrics(w io.Writer, widths []int) error {
+	buf := make([]byte, len(widths))
+	for i, width := range widths {
+		if width < 0 || width > 0xFF {
+			return fmt.Errorf("%w: glyph %d has width %d", ErrUnpatchable, i, width)
+		}
+		buf[i] = uint8(width)
+	}
+
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ApplyBDF replaces the glyphs of f with the ones in a BDF font that have the same codepoint in f's charmap. Glyphs
+// the BDF font doesn't have are left as they are.
+func (f *Font) ApplyBDF(bf *bdf.Font) error {
+	indexes := map[rune]int{}
+	for i, c := range f.Charmap {
+		indexes[c] = i
+	}
+
+	for _, g := range bf.Glyphs {
+		i, ok := indexes[g.Encoding]
+		if !ok {
+			return fmt.Errorf("%w: U+%04X is not in the charmap", ErrUnpatchable, g.Encoding)
+		}
+
+		cell := bf.Cell(g)
+		if cell.Rect.Size() != f.Glyphs[i].Rect.Size() {
+			return fmt.Errorf("%w: U+%04X is %dx%d, must be %dx%d", ErrUnpatchable, g.Encoding, cell.Rect.Dx(), cell.Rect.Dy(), f.Glyphs[i].Rect.Dx(), f.Glyphs[i].Rect.Dy())
+		}
+
+		f.Glyphs[i] = cell
+		f.Widths[i] = g.Width
+	}
+
+	return nil
+}
+
+// patchGlyphs writes each glyph of f over the one in old that it differs from once encoded, so glyphs that weren't
+// edited keep their original bytes.
+func patchGlyphs(rom []byte, f *Font, old *Font, glyphOffset func(i int) int64, encode func(io.Writer, *image.Alpha) error) error {
+	for i, glyph := range f.Glyphs {
+		var newBuf bytes.Buffer
+		if err := encode(&newBuf, glyph); err != nil {
+			return fmt.Errorf("%w while encoding glyph %d", err, i)
+		}
+
+		var oldBuf bytes.Buffer
+		if err := encode(&oldBuf, old.Glyphs[i]); err != nil {
+			return fmt.Errorf("%w while encoding glyph %d", err, i)
+		}
+
+		if bytes.Equal(newBuf.Bytes(), oldBuf.Bytes()) {
+			continue
+		}
+
+		offset := glyphOffset(i)
+		if offset < 0 {
+			return fmt.Errorf("%w: glyph %d isn't stored in the rom", ErrUnpatchable, i)
+		}
+
+		if offset+int64(newBuf.Len()) > int64(len(rom)) {
+			return fmt.Errorf("%w: glyph %d is past the end of the rom", ErrUnpatchable, i)
+		}
+		copy(rom[offset:], newBuf.Bytes())
+	}
+
+	return nil
+}
+
+// PatchTallFont writes the glyphs of f into the tall font in rom. The tall font has a fixed width, so f.Widths is
+// ignored.
+func PatchTallFont(rom []byte, ri ROMInfo, f *Font) error {
+	old, err := ReadTallFont(bytes.NewReader(rom), ri)
+	if err != nil {
+		return fmt.Errorf("%w while reading tall font", err)
+	}
+
+	if ri.TallOffset+4 > int64(len(rom)) {
+		return fmt.Errorf("%w: tall font pointer is past the end of the rom", ErrUnpatchable)
+	}
+	base := int64(binary.LittleEndian.Uint32(rom[ri.TallOffset:]) &^ 0x08000000)
+
+	return patchGlyphs(rom, f, old, func(i int) int64 {
+		return base + int64(i)*0x40
+	}, func(w io.Writer, glyph *image.Alpha) error {
+		return WriteGlyph(w, glyph, 1)
+	})
+}
+
+// PatchTall2Font writes the glyphs and widths of f into the tall2 font in rom. The first glyph isn't stored, so it must
+// stay blank.
+func PatchTall2Font(rom []byte, ri ROMInfo, f *Font) error {
+	old, err := ReadTall2Font(bytes.NewReader(rom), ri)
+	if err != nil {
+		return fmt.Errorf("%w while reading tall2 font", err)
+	}
+
+	if err := patchGlyphs(rom, f, old, func(i int) int64 {
+		if i == 0 {
+			return -1
+		}
+		return ri.Tall2Offset + int64(i)*0x60
+	}, Write16x12Glyph); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf, f.Widths); err != nil {
+		return fmt.Errorf("%w while encoding tall2 font metrics", err)
+	}
+	copy(rom[ri.Tall2MetricsOffset:], buf.Bytes())
+
+	return nil
+}
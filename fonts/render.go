@@ -0,0 +1,120 @@
+package fonts
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"strings"
+)
+
+var ErrUnmappedRune = errors.New("fonts: rune not in charmap")
+
+func (f *Font) runeIndexes() map[rune]int {
+	runeIndexes := map[rune]int{}
+	for i := len(f.Charmap) - 1; i >= 0; i-- {
+		runeIndexes[f.Charmap[i]] = i
+	}
+	return runeIndexes
+}
+
+func glyphIndexes(runeIndexes map[rune]int, s string) ([]int, error) {
+	var indexes []int
+	for _, c := range s {
+		idx, ok := runeIndexes[c]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnmappedRune, c)
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, nil
+}
+
+func (f *Font) measure(indexes []int) int {
+	w := 0
+	for _, idx := range indexes {
+		w += f.Widths[idx]
+	}
+	return w
+}
+
+// wrap breaks a paragraph into lines no wider than wrapWidth, preferring to break at spaces. Words wider than a line on
+// their own are broken between glyphs.
+func (f *Font) wrap(runeIndexes map[rune]int, paragraph string, wrapWidth int) ([][]int, error) {
+	words := strings.Split(paragraph, " ")
+
+	// Fonts such as the tiny digits have no space, so only look it up if it's needed.
+	var space []int
+	if len(words) > 1 {
+		var err error
+		if space, err = glyphIndexes(runeIndexes, " "); err != nil {
+			return nil, err
+		}
+	}
+
+	var lines [][]int
+	var line []int
+	for i, word := range words {
+		indexes, err := glyphIndexes(runeIndexes, word)
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			if wrapWidth <= 0 || f.measure(line)+f.measure(space)+f.measure(indexes) <= wrapWidth {
+				line = append(line, space...)
+				line = append(line, indexes...)
+				continue
+			}
+			lines = append(lines, line)
+			line = nil
+		}
+
+		for _, idx := range indexes {
+			if wrapWidth > 0 && len(line) > 0 && f.measure(line)+f.Widths[idx] > wrapWidth {
+				lines = append(lines, line)
+				line = nil
+			}
+			line = append(line, idx)
+		}
+	}
+	lines = append(lines, line)
+
+	return lines, nil
+}
+
+// Render lays out UTF-8 text with the font and draws it. Lines are broken at newlines and, if wrapWidth is positive,
+// wrapped to at most wrapWidth pixels wide. Every rune must be in the font's charmap.
+func (f *Font) Render(s string, wrapWidth int) (*image.Alpha, error) {
+	runeIndexes := f.runeIndexes()
+
+	var lines [][]int
+	for _, paragraph := range strings.Split(s, "\n") {
+		paragraphLines, err := f.wrap(runeIndexes, paragraph, wrapWidth)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, paragraphLines...)
+	}
+
+	width := wrapWidth
+	if width <= 0 {
+		for _, line := range lines {
+			if w := f.measure(line); w > width {
+				width = w
+			}
+		}
+	}
+
+	img := image.NewAlpha(image.Rect(0, 0, width, len(lines)*f.LineHeight))
+	for j, line := range lines {
+		x := 0
+		for _, idx := range line {
+			glyph := f.Glyphs[idx]
+			draw.Draw(img, glyph.Rect.Add(image.Point{x, j * f.LineHeight}), glyph, glyph.Rect.Min, draw.Over)
+			x += f.Widths[idx]
+		}
+	}
+
+	return img, nil
+}
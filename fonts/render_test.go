@@ -0,0 +1,103 @@
+package fonts
+
+import (
+	"errors"
+	"image"
+	"reflect"
+	"testing"
+)
+
+// testFont has glyphs as wide as their advances with only their top left pixel opaque, so where each glyph is drawn
+// can be told apart.
+func testFont(charmap string, widths ...int) *Font {
+	f := &Font{Charmap: []rune(charmap), Widths: widths, LineHeight: 4}
+	for _, w := range widths {
+		glyph := image.NewAlpha(image.Rect(0, 0, w, f.LineHeight))
+		glyph.Pix[0] = 0xff
+		f.Glyphs = append(f.Glyphs, glyph)
+	}
+	return f
+}
+
+func TestWrap(t *testing.T) {
+	// a, b and space are glyphs 0, 1 and 2.
+	f := testFont("ab ", 3, 5, 2)
+
+	for _, tc := range []struct {
+		paragraph string
+		wrapWidth int
+		want      [][]int
+	}{
+		{"ab ab", 0, [][]int{{0, 1, 2, 0, 1}}},
+		{"ab ab", 18, [][]int{{0, 1, 2, 0, 1}}},
+		{"ab ab", 17, [][]int{{0, 1}, {0, 1}}},
+		{"abab", 10, [][]int{{0, 1}, {0, 1}}},
+		{"a abab", 11, [][]int{{0}, {0, 1, 0}, {1}}},
+		{"", 10, [][]int{nil}},
+	} {
+		got, err := f.wrap(f.runeIndexes(), tc.paragraph, tc.wrapWidth)
+		if err != nil {
+			t.Fatalf("wrap(%q, %d) = %v", tc.paragraph, tc.wrapWidth, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("wrap(%q, %d) = %v, want %v", tc.paragraph, tc.wrapWidth, got, tc.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	f := testFont("ab ", 3, 5, 2)
+
+	img, err := f.Render("ab b\nba", 0)
+	if err != nil {
+		t.Fatalf("Render() = %v", err)
+	}
+
+	if want := image.Rect(0, 0, 3+5+2+5, 2*f.LineHeight); img.Rect != want {
+		t.Errorf("Render() is %v, want %v", img.Rect, want)
+	}
+
+	// Glyphs are drawn at their origins, each advancing by its own width.
+	for _, pt := range []image.Point{{0, 0}, {3, 0}, {10, 0}, {0, 4}, {5, 4}} {
+		if a := img.AlphaAt(pt.X, pt.Y).A; a != 0xff {
+			t.Errorf("Render() has no glyph drawn at %v", pt)
+		}
+	}
+	opaque := 0
+	for _, a := range img.Pix {
+		if a != 0 {
+			opaque++
+		}
+	}
+	if opaque != 6 {
+		t.Errorf("Render() has %d opaque pixels, want 6", opaque)
+	}
+}
+
+func TestRenderWrapWidth(t *testing.T) {
+	f := testFont("ab ", 3, 5, 2)
+
+	img, err := f.Render("ab ab", 10)
+	if err != nil {
+		t.Fatalf("Render() = %v", err)
+	}
+	if want := image.Rect(0, 0, 10, 2*f.LineHeight); img.Rect != want {
+		t.Errorf("Render() is %v, want %v", img.Rect, want)
+	}
+}
+
+func TestRenderNoSpace(t *testing.T) {
+	f := testFont("0123456789", 6, 6, 6, 6, 6, 6, 6, 6, 6, 6)
+
+	img, err := f.Render("0123\n45", 0)
+	if err != nil {
+		t.Fatalf("Render() = %v", err)
+	}
+	if want := image.Rect(0, 0, 24, 2*f.LineHeight); img.Rect != want {
+		t.Errorf("Render() is %v, want %v", img.Rect, want)
+	}
+
+	if _, err := f.Render("1 2", 0); !errors.Is(err, ErrUnmappedRune) {
+		t.Errorf("Render() with a space = %v, want %v", err, ErrUnmappedRune)
+	}
+}
@@ -0,0 +1,381 @@
+package ttf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/murkland/bnrom/fonts/bdf"
+)
+
+type Glyph struct {
+	Codepoint rune
+
+	// Advance is how far to advance after drawing the glyph, in pixels.
+	Advance int
+
+	Image *image.Alpha
+}
+
+// UnitsPerPixel is how many font units each pixel of a glyph takes up.
+const UnitsPerPixel = 64
+
+var ErrUnsupportedCodepoint = errors.New("ttf: codepoint outside the basic multilingual plane")
+
+type contour [4]image.Point
+
+// outline traces the pixels of a glyph that are at least half opaque as rectangles, one per horizontal run, in font
+// units. Glyph images are laid out as in BDF, with bbox giving the offset of the image from the origin.
+func outline(img *image.Alpha, bbox image.Rectangle) []contour {
+	var contours []contour
+
+	for y := 0; y < img.Rect.Dy(); y++ {
+		top := (bbox.Min.Y + img.Rect.Dy() - y) * UnitsPerPixel
+		bottom := top - UnitsPerPixel
+
+		for x := 0; x < img.Rect.Dx(); {
+			if img.AlphaAt(img.Rect.Min.X+x, img.Rect.Min.Y+y).A < 0x80 {
+				x++
+				continue
+			}
+
+			start := x
+			for x < img.Rect.Dx() && img.AlphaAt(img.Rect.Min.X+x, img.Rect.Min.Y+y).A >= 0x80 {
+				x++
+			}
+
+			left := (bbox.Min.X + start) * UnitsPerPixel
+			right := (bbox.Min.X + x) * UnitsPerPixel
+
+			// Clockwise, for an outer contour.
+			contours = append(contours, contour{{left, top}, {right, top}, {right, bottom}, {left, bottom}})
+		}
+	}
+
+	return contours
+}
+
+type glyf struct {
+	contours []contour
+	bounds   image.Rectangle
+}
+
+func (g glyf) encode() []byte {
+	if len(g.contours) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, []int16{int16(len(g.contours)), int16(g.bounds.Min.X), int16(g.bounds.Min.Y), int16(g.bounds.Max.X), int16(g.bounds.Max.Y)})
+	for i := range g.contours {
+		binary.Write(&buf, binary.BigEndian, uint16(i*4+3))
+	}
+
+	// No instructions.
+	binary.Write(&buf, binary.BigEndian, uint16(0))
+
+	// Every point is on the curve, with coordinates as 16-bit deltas.
+	for range g.contours {
+		buf.Write([]byte{0x01, 0x01, 0x01, 0x01})
+	}
+
+	var prev image.Point
+	var ys []int16
+	for _, c := range g.contours {
+		for _, pt := range c {
+			binary.Write(&buf, binary.BigEndian, int16(pt.X-prev.X))
+			ys = append(ys, int16(pt.Y-prev.Y))
+			prev = pt
+		}
+	}
+	binary.Write(&buf, binary.BigEndian, ys)
+
+	for buf.Len()%4 != 0 {
+		buf.WriteByte(0)
+	}
+
+	return buf.Bytes()
+}
+
+// cmap builds a format 4 character map. codepoints are sorted and map to the glyph IDs in gids.
+func cmap(codepoints []rune, gids []int) []byte {
+	type segment struct {
+		start, end uint16
+		delta      uint16
+	}
+
+	var segments []segment
+	for i, c := range codepoints {
+		delta := uint16(gids[i] - int(c))
+		if n := len(segments); n > 0 && segments[n-1].end+1 == uint16(c) && segments[n-1].delta == delta {
+			segments[n-1].end = uint16(c)
+			continue
+		}
+		segments = append(segments, segment{uint16(c), uint16(c), delta})
+	}
+	segments = append(segments, segment{0xFFFF, 0xFFFF, 1})
+
+	segCount := len(segments)
+	searchRange := 2
+	entrySelector := 0
+	for searchRange*2 <= segCount*2 {
+		searchRange *= 2
+		entrySelector++
+	}
+
+	var sub bytes.Buffer
+	length := 16 + segCount*8
+	binary.Write(&sub, binary.BigEndian, []uint16{4, uint16(length), 0, uint16(segCount * 2), uint16(searchRange), uint16(entrySelector), uint16(segCount*2 - searchRange)})
+	for _, s := range segments {
+		binary.Write(&sub, binary.BigEndian, s.end)
+	}
+	binary.Write(&sub, binary.BigEndian, uint16(0))
+	for _, s := range segments {
+		binary.Write(&sub, binary.BigEndian, s.start)
+	}
+	for _, s := range segments {
+		binary.Write(&sub, binary.BigEndian, s.delta)
+	}
+	for range segments {
+		binary.Write(&sub, binary.BigEndian, uint16(0))
+	}
+
+	var buf bytes.Buffer
+	// One subtable, for Windows Unicode BMP.
+	binary.Write(&buf, binary.BigEndian, []uint16{0, 1, 3, 1})
+	binary.Write(&buf, binary.BigEndian, uint32(12))
+	buf.Write(sub.Bytes())
+	return buf.Bytes()
+}
+
+func name(family string) []byte {
+	psName := strings.ReplaceAll(family, " ", "")
+	strs := []struct {
+		id uint16
+		s  string
+	}{
+		{1, family},
+		{2, "Regular"},
+		{3, psName + "-Regular"},
+		{4, family},
+		{5, "Version 1.0"},
+		{6, psName},
+	}
+
+	var storage bytes.Buffer
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, []uint16{0, uint16(len(strs)), uint16(6 + len(strs)*12)})
+	for _, s := range strs {
+		encoded := utf16.Encode([]rune(s.s))
+		binary.Write(&buf, binary.BigEndian, []uint16{3, 1, 0x0409, s.id, uint16(len(encoded) * 2), uint16(storage.Len())})
+		binary.Write(&storage, binary.BigEndian, encoded)
+	}
+	buf.Write(storage.Bytes())
+	return buf.Bytes()
+}
+
+func checksum(b []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(b); i += 4 {
+		var word [4]byte
+		copy(word[:], b[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+// Write writes bitmap glyphs as a TrueType font with one square outline per run of opaque pixels. Vertical metrics come
+// from p's ascent and descent, and glyphs are placed relative to the baseline using p's bounding box, as in BDF.
+func Write(w io.Writer, family string, p bdf.Properties, glyphs []Glyph) error {
+	sorted := make([]int, len(glyphs))
+	for i := range sorted {
+		sorted[i] = i
+	}
+	sort.Slice(sorted, func(i, j int) bool { return glyphs[sorted[i]].Codepoint < glyphs[sorted[j]].Codepoint })
+
+	codepoints := make([]rune, len(glyphs))
+	gids := make([]int, len(glyphs))
+	for i, idx := range sorted {
+		c := glyphs[idx].Codepoint
+		if c < 0 || c >= 0xFFFF {
+			return fmt.Errorf("%w: U+%04X", ErrUnsupportedCodepoint, c)
+		}
+		codepoints[i] = c
+		gids[i] = idx + 1
+	}
+
+	ascent := p.Ascent * UnitsPerPixel
+	descent := p.Descent * UnitsPerPixel
+	unitsPerEm := ascent + descent
+
+	// Glyph 0 is .notdef, left blank.
+	glyfs := make([]glyf, len(glyphs)+1)
+	advances := make([]int, len(glyphs)+1)
+	advances[0] = p.BBox.Dx() * UnitsPerPixel
+
+	var bounds image.Rectangle
+	maxAdvance, maxContours := advances[0], 0
+	for i, g := range glyphs {
+		contours := outline(g.Image, p.BBox)
+
+		var gb image.Rectangle
+		for j, c := range contours {
+			cb := image.Rectangle{c[3], c[1]}
+			if j == 0 {
+				gb = cb
+			} else {
+				gb = gb.Union(cb)
+			}
+		}
+
+		glyfs[i+1] = glyf{contours, gb}
+		advances[i+1] = g.Advance * UnitsPerPixel
+
+		if len(contours) > 0 {
+			if bounds.Empty() {
+				bounds = gb
+			} else {
+				bounds = bounds.Union(gb)
+			}
+		}
+		if advances[i+1] > maxAdvance {
+			maxAdvance = advances[i+1]
+		}
+		if len(contours) > maxContours {
+			maxContours = len(contours)
+		}
+	}
+
+	var glyfBuf, locaBuf, hmtxBuf bytes.Buffer
+	minLSB, minRSB := 0, 0
+	for i, g := range glyfs {
+		binary.Write(&locaBuf, binary.BigEndian, uint32(glyfBuf.Len()))
+		glyfBuf.Write(g.encode())
+
+		lsb := g.bounds.Min.X
+		binary.Write(&hmtxBuf, binary.BigEndian, []int16{int16(advances[i]), int16(lsb)})
+		if len(g.contours) > 0 {
+			if lsb < minLSB {
+				minLSB = lsb
+			}
+			if rsb := advances[i] - g.bounds.Max.X; rsb < minRSB {
+				minRSB = rsb
+			}
+		}
+	}
+	binary.Write(&locaBuf, binary.BigEndian, uint32(glyfBuf.Len()))
+
+	var head bytes.Buffer
+	binary.Write(&head, binary.BigEndian, []uint32{0x00010000, 0x00010000, 0, 0x5F0F3CF5})
+	binary.Write(&head, binary.BigEndian, []uint16{0x000B, uint16(unitsPerEm)})
+	binary.Write(&head, binary.BigEndian, []int64{0, 0})
+	binary.Write(&head, binary.BigEndian, []int16{int16(bounds.Min.X), int16(bounds.Min.Y), int16(bounds.Max.X), int16(bounds.Max.Y)})
+	binary.Write(&head, binary.BigEndian, []uint16{0, uint16(p.Ascent + p.Descent)})
+	binary.Write(&head, binary.BigEndian, []int16{2, 1, 0})
+
+	var hhea bytes.Buffer
+	binary.Write(&hhea, binary.BigEndian, uint32(0x00010000))
+	binary.Write(&hhea, binary.BigEndian, []int16{int16(ascent), int16(-descent), 0})
+	binary.Write(&hhea, binary.BigEndian, uint16(maxAdvance))
+	binary.Write(&hhea, binary.BigEndian, []int16{int16(minLSB), int16(minRSB), int16(bounds.Max.X), 1, 0, 0, 0, 0, 0, 0, 0})
+	binary.Write(&hhea, binary.BigEndian, uint16(len(glyfs)))
+
+	var maxp bytes.Buffer
+	binary.Write(&maxp, binary.BigEndian, uint32(0x00010000))
+	binary.Write(&maxp, binary.BigEndian, []uint16{uint16(len(glyfs)), uint16(maxContours * 4), uint16(maxContours), 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0})
+
+	var firstChar, lastChar uint16
+	if len(codepoints) > 0 {
+		firstChar, lastChar = uint16(codepoints[0]), uint16(codepoints[len(codepoints)-1])
+	}
+	em := int16(unitsPerEm)
+	var os2 bytes.Buffer
+	binary.Write(&os2, binary.BigEndian, uint16(4))
+	binary.Write(&os2, binary.BigEndian, int16(p.BBox.Dx()*UnitsPerPixel))
+	binary.Write(&os2, binary.BigEndian, []uint16{400, 5, 0})
+	binary.Write(&os2, binary.BigEndian, []int16{em / 2, em / 2, 0, em / 8, em / 2, em / 2, 0, em / 3, UnitsPerPixel, int16(ascent / 3), 0})
+	os2.Write(make([]byte, 10))
+	binary.Write(&os2, binary.BigEndian, []uint32{0, 0, 0, 0})
+	os2.WriteString("    ")
+	binary.Write(&os2, binary.BigEndian, []uint16{0x0040, firstChar, lastChar})
+	binary.Write(&os2, binary.BigEndian, []int16{int16(ascent), int16(-descent), 0})
+	binary.Write(&os2, binary.BigEndian, []uint16{uint16(ascent), uint16(descent)})
+	binary.Write(&os2, binary.BigEndian, []uint32{1, 0})
+	binary.Write(&os2, binary.BigEndian, []int16{int16(ascent / 2), int16(ascent)})
+	binary.Write(&os2, binary.BigEndian, []uint16{0, 0x20, 0})
+
+	// The font is fixed pitch if every glyph other than .notdef has the same advance.
+	isFixedPitch := uint32(1)
+	for _, advance := range advances[1:] {
+		if advance != advances[1] {
+			isFixedPitch = 0
+			break
+		}
+	}
+
+	var post bytes.Buffer
+	binary.Write(&post, binary.BigEndian, []uint32{0x00030000, 0})
+	binary.Write(&post, binary.BigEndian, []int16{-UnitsPerPixel, UnitsPerPixel})
+	binary.Write(&post, binary.BigEndian, []uint32{isFixedPitch, 0, 0, 0, 0})
+
+	tables := []struct {
+		tag  string
+		data []byte
+	}{
+		{"OS/2", os2.Bytes()},
+		{"cmap", cmap(codepoints, gids)},
+		{"glyf", glyfBuf.Bytes()},
+		{"head", head.Bytes()},
+		{"hhea", hhea.Bytes()},
+		{"hmtx", hmtxBuf.Bytes()},
+		{"loca", locaBuf.Bytes()},
+		{"maxp", maxp.Bytes()},
+		{"name", name(family)},
+		{"post", post.Bytes()},
+	}
+
+	numTables := len(tables)
+	searchRange, entrySelector := 1, 0
+	for searchRange*2 <= numTables {
+		searchRange *= 2
+		entrySelector++
+	}
+	searchRange *= 16
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.BigEndian, uint32(0x00010000))
+	binary.Write(&out, binary.BigEndian, []uint16{uint16(numTables), uint16(searchRange), uint16(entrySelector), uint16(numTables*16 - searchRange)})
+
+	offset := 12 + numTables*16
+	headOffset := 0
+	for _, t := range tables {
+		if t.tag == "head" {
+			headOffset = offset
+		}
+		out.WriteString(t.tag)
+		binary.Write(&out, binary.BigEndian, []uint32{checksum(t.data), uint32(offset), uint32(len(t.data))})
+		offset += (len(t.data) + 3) &^ 3
+	}
+
+	for _, t := range tables {
+		out.Write(t.data)
+		for out.Len()%4 != 0 {
+			out.WriteByte(0)
+		}
+	}
+
+	font := out.Bytes()
+	binary.BigEndian.PutUint32(font[headOffset+8:], 0xB1B0AFBA-checksum(font))
+
+	if _, err := w.Write(font); err != nil {
+		return err
+	}
+
+	return nil
+}
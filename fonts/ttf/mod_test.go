@@ -0,0 +1,109 @@
+package ttf
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"github.com/murkland/bnrom/fonts/bdf"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+var testProperties = bdf.Properties{
+	Size: 12,
+	DPI:  image.Point{72, 72},
+	BPP:  1,
+
+	BBox:    image.Rect(0, -2, 8, 10),
+	Ascent:  10,
+	Descent: 2,
+}
+
+// testGlyph returns a glyph drawn in the bounding box of testProperties, with the pixels in r opaque.
+func testGlyph(c rune, advance int, r image.Rectangle) Glyph {
+	img := image.NewAlpha(image.Rect(0, 0, testProperties.BBox.Dx(), testProperties.BBox.Dy()))
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Pix[img.PixOffset(x, y)] = 0xff
+		}
+	}
+	return Glyph{c, advance, img}
+}
+
+func writeAndParse(t *testing.T, glyphs []Glyph) *sfnt.Font {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "Test", testProperties, glyphs); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+
+	f, err := sfnt.Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("sfnt.Parse() = %v", err)
+	}
+	return f
+}
+
+func TestWrite(t *testing.T) {
+	// Out of codepoint order, with a gap in the codepoints and a blank glyph.
+	glyphs := []Glyph{
+		testGlyph('B', 7, image.Rect(1, 2, 6, 10)),
+		testGlyph('A', 6, image.Rect(0, 0, 5, 12)),
+		testGlyph(' ', 4, image.Rectangle{}),
+		testGlyph('あ', 8, image.Rect(0, 1, 8, 9)),
+	}
+
+	f := writeAndParse(t, glyphs)
+
+	if got, want := f.NumGlyphs(), len(glyphs)+1; got != want {
+		t.Errorf("NumGlyphs() = %d, want %d", got, want)
+	}
+
+	var b sfnt.Buffer
+	ppem := fixed.I(testProperties.Ascent + testProperties.Descent)
+	for i, g := range glyphs {
+		gid, err := f.GlyphIndex(&b, g.Codepoint)
+		if err != nil {
+			t.Fatalf("GlyphIndex(%q) = %v", g.Codepoint, err)
+		}
+		if want := sfnt.GlyphIndex(i + 1); gid != want {
+			t.Errorf("GlyphIndex(%q) = %d, want %d", g.Codepoint, gid, want)
+		}
+
+		advance, err := f.GlyphAdvance(&b, gid, ppem, font.HintingNone)
+		if err != nil {
+			t.Fatalf("GlyphAdvance(%q) = %v", g.Codepoint, err)
+		}
+		if want := fixed.I(g.Advance); advance != want {
+			t.Errorf("GlyphAdvance(%q) = %v, want %v", g.Codepoint, advance, want)
+		}
+	}
+
+	for _, c := range []rune{'C', '@', 'い'} {
+		gid, err := f.GlyphIndex(&b, c)
+		if err != nil {
+			t.Fatalf("GlyphIndex(%q) = %v", c, err)
+		}
+		if gid != 0 {
+			t.Errorf("GlyphIndex(%q) = %d, want 0", c, gid)
+		}
+	}
+
+	if f.PostTable().IsFixedPitch {
+		t.Errorf("IsFixedPitch = true for glyphs with different advances")
+	}
+}
+
+func TestWriteFixedPitch(t *testing.T) {
+	f := writeAndParse(t, []Glyph{
+		testGlyph('0', 6, image.Rect(0, 0, 5, 10)),
+		testGlyph('1', 6, image.Rect(2, 0, 3, 10)),
+	})
+
+	if !f.PostTable().IsFixedPitch {
+		t.Errorf("IsFixedPitch = false for glyphs with the same advance")
+	}
+}
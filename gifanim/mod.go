@@ -0,0 +1,68 @@
+package gifanim
+
+import (
+	"image"
+	"image/gif"
+	"io"
+)
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// LCM returns the least common multiple of two positive numbers, such as the length of a loop in which animations of
+// lengths a and b both loop a whole number of times.
+func LCM(a, b int) int {
+	return a / gcd(a, b) * b
+}
+
+// Animation is a sequence of frames that loops.
+type Animation struct {
+	Frames []*image.Paletted
+
+	// Delays are how long each frame is shown for, in 1/60ths of a second.
+	Delays []int
+}
+
+// Record records one loop of an animation lasting loop 1/60ths of a second. frame is called at time 0 and then again
+// whenever the frame it last returned says the picture next changes, until the loop is over.
+func Record(loop int, frame func(t int) (img *image.Paletted, next int, err error)) (*Animation, error) {
+	anim := &Animation{}
+	for t := 0; t < loop; {
+		img, next, err := frame(t)
+		if err != nil {
+			return nil, err
+		}
+
+		if next <= t || next > loop {
+			next = loop
+		}
+
+		anim.Frames = append(anim.Frames, img)
+		anim.Delays = append(anim.Delays, next-t)
+		t = next
+	}
+	return anim, nil
+}
+
+// WriteGIF writes an animation as a looping GIF. GIF delays are in 1/100ths of a second, so each frame's delay is
+// rounded such that the loop as a whole keeps its length.
+func WriteGIF(w io.Writer, anim *Animation) error {
+	g := &gif.GIF{}
+
+	elapsed := 0
+	for i, frame := range anim.Frames {
+		start := (elapsed*100 + 30) / 60
+		elapsed += anim.Delays[i]
+		end := (elapsed*100 + 30) / 60
+
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, end-start)
+		g.Disposal = append(g.Disposal, gif.DisposalBackground)
+	}
+
+	return gif.EncodeAll(w, g)
+}
@@ -6,7 +6,8 @@ require (
 	github.com/murkland/gbarom v0.0.0-20220305211653-6a9b5253e1ca
 	github.com/murkland/pngchunks v0.0.0-20220305211659-3f322c254e68
 	github.com/schollz/progressbar/v3 v3.8.6
-	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
+	golang.org/x/image v0.18.0
+	golang.org/x/sync v0.7.0
 )
 
 require (
@@ -16,4 +17,5 @@ require (
 	golang.org/x/crypto v0.0.0-20220214200702-86341886e292 // indirect
 	golang.org/x/sys v0.0.0-20220209214540-3681064d5158 // indirect
 	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211 // indirect
+	golang.org/x/text v0.16.0 // indirect
 )
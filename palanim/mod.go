@@ -0,0 +1,100 @@
+package palanim
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+
+	"github.com/murkland/bnrom/battletiles"
+)
+
+// Frame is one step of a palette animation: the 16 colors of the palbank and how long they're shown for.
+type Frame struct {
+	Colors color.Palette
+
+	// Duration is in 1/60ths of a second.
+	Duration int
+}
+
+// Animation is a palbank's colors changing over time. It loops once it reaches its last frame.
+type Animation struct {
+	Name   string
+	Frames []Frame
+}
+
+// Length returns how long one loop of the animation lasts, in 1/60ths of a second.
+func (a Animation) Length() int {
+	n := 0
+	for _, f := range a.Frames {
+		n += f.Duration
+	}
+	return n
+}
+
+// At returns the index of the frame shown t frames in, along with when the next frame starts.
+func (a Animation) At(t int) (int, int) {
+	length := a.Length()
+	base := t / length * length
+	elapsed := base
+	for i, f := range a.Frames {
+		elapsed += f.Duration
+		if t < elapsed {
+			return i, elapsed
+		}
+	}
+	return len(a.Frames) - 1, base + length
+}
+
+// FromBattletiles returns the palette animations of the battletiles panels that have them, red side first, with the
+// palbanks and frame times given by the layout. They aren't decoded from the game's palette animation scripts, which
+// haven't been located.
+func FromBattletiles(palbanks []color.Palette, l *battletiles.Layout) ([]Animation, error) {
+	var anims []Animation
+	for _, pa := range l.PalbankAnimations() {
+		side := "red"
+		if pa.Blue {
+			side = "blue"
+		}
+
+		anim := Animation{fmt.Sprintf("%s-%s", pa.Type, side), nil}
+		for _, palbank := range pa.Palbanks {
+			if palbank >= len(palbanks) {
+				return nil, fmt.Errorf("%s palbank %d is past the end of the palbanks", anim.Name, palbank)
+			}
+			anim.Frames = append(anim.Frames, Frame{palbanks[palbank], pa.FrameTime})
+		}
+		anims = append(anims, anim)
+	}
+	return anims, nil
+}
+
+// WriteJSON writes animations as JSON, with colors as #rrggbb strings.
+func WriteJSON(w io.Writer, anims []Animation) error {
+	type frame struct {
+		Colors   []string
+		Duration int
+	}
+
+	type animation struct {
+		Name   string
+		Frames []frame
+	}
+
+	records := make([]animation, len(anims))
+	for i, a := range anims {
+		records[i] = animation{a.Name, make([]frame, len(a.Frames))}
+		for j, f := range a.Frames {
+			colors := make([]string, len(f.Colors))
+			for k, c := range f.Colors {
+				r, g, b, _ := c.RGBA()
+				colors[k] = fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+			}
+			records[i].Frames[j] = frame{colors, f.Duration}
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
@@ -0,0 +1,78 @@
+package palanim
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/murkland/bnrom/gifanim"
+)
+
+const swatchSize = 8
+
+// maxAnimations is how many animations fit in a 256 color palette at 16 colors each.
+const maxAnimations = 16
+
+var ErrTooManyAnimations = errors.New("palanim: more than 16 animations to render")
+
+// Swatches returns an image with a row of 16 swatches for each animation. Row j is drawn with colors j*16 to j*16+15,
+// which Render fills in from animation j. Color 0 of each palbank is included, even though it's transparent in game.
+func Swatches(anims []Animation) (*image.Paletted, error) {
+	if len(anims) > maxAnimations {
+		return nil, ErrTooManyAnimations
+	}
+
+	palette := make(color.Palette, 256)
+	for i := range palette {
+		palette[i] = color.RGBA{}
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, 16*swatchSize, len(anims)*swatchSize), palette)
+	for j := range anims {
+		for y := j * swatchSize; y < (j+1)*swatchSize; y++ {
+			for x := 0; x < 16*swatchSize; x++ {
+				img.Pix[y*img.Stride+x] = uint8(j*16 + x/swatchSize)
+			}
+		}
+	}
+	return img, nil
+}
+
+// Render renders one full loop of every animation over img, as drawn by Swatches. A frame is emitted whenever any
+// animation changes.
+func Render(img *image.Paletted, anims []Animation) (*gifanim.Animation, error) {
+	if len(anims) > maxAnimations {
+		return nil, ErrTooManyAnimations
+	}
+
+	loop := 1
+	for _, a := range anims {
+		length := a.Length()
+		if length <= 0 {
+			return nil, fmt.Errorf("%s has no frames", a.Name)
+		}
+		loop = gifanim.LCM(loop, length)
+	}
+
+	return gifanim.Record(loop, func(t int) (*image.Paletted, int, error) {
+		next := loop
+
+		palette := append(make(color.Palette, 0, 256), img.Palette...)
+		for len(palette) < 256 {
+			palette = append(palette, color.RGBA{})
+		}
+
+		for j, a := range anims {
+			i, change := a.At(t)
+			if change < next {
+				next = change
+			}
+			copy(palette[j*16:(j+1)*16], a.Frames[i].Colors)
+		}
+
+		frame := *img
+		frame.Palette = palette
+		return &frame, next, nil
+	})
+}
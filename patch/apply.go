@@ -0,0 +1,191 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+var ErrMalformed = errors.New("patch: malformed patch")
+var ErrChecksum = errors.New("patch: checksum mismatch")
+
+// ApplyIPS applies an IPS patch to source, including RLE records.
+func ApplyIPS(source []byte, patch []byte) ([]byte, error) {
+	if !bytes.HasPrefix(patch, []byte("PATCH")) {
+		return nil, ErrMalformed
+	}
+	patch = patch[5:]
+
+	target := append([]byte(nil), source...)
+	write := func(offset int, data []byte) {
+		if end := offset + len(data); end > len(target) {
+			target = append(target, make([]byte, end-len(target))...)
+		}
+		copy(target[offset:], data)
+	}
+
+	for {
+		if len(patch) < 3 {
+			return nil, ErrMalformed
+		}
+		if string(patch[:3]) == "EOF" {
+			break
+		}
+
+		if len(patch) < 5 {
+			return nil, ErrMalformed
+		}
+		offset := int(patch[0])<<16 | int(patch[1])<<8 | int(patch[2])
+		size := int(patch[3])<<8 | int(patch[4])
+		patch = patch[5:]
+
+		if size == 0 {
+			if len(patch) < 3 {
+				return nil, ErrMalformed
+			}
+			write(offset, bytes.Repeat(patch[2:3], int(patch[0])<<8|int(patch[1])))
+			patch = patch[3:]
+			continue
+		}
+
+		if len(patch) < size {
+			return nil, ErrMalformed
+		}
+		write(offset, patch[:size])
+		patch = patch[size:]
+	}
+
+	return target, nil
+}
+
+const (
+	bpsSourceCopy = 2
+	bpsTargetCopy = 3
+)
+
+func readBPSNumber(r *bytes.Reader) (uint64, error) {
+	var n uint64
+	shift := uint64(1)
+	for {
+		x, err := r.ReadByte()
+		if err != nil {
+			return 0, ErrMalformed
+		}
+		n += uint64(x&0x7F) * shift
+		if x&0x80 != 0 {
+			return n, nil
+		}
+		shift <<= 7
+		n += shift
+	}
+}
+
+func readBPSOffset(r *bytes.Reader) (int, error) {
+	n, err := readBPSNumber(r)
+	if err != nil {
+		return 0, err
+	}
+	if n&1 != 0 {
+		return -int(n >> 1), nil
+	}
+	return int(n >> 1), nil
+}
+
+// ApplyBPS applies a BPS patch to source, checking the source, target and patch checksums.
+func ApplyBPS(source []byte, patch []byte) ([]byte, error) {
+	if len(patch) < 4+12 || !bytes.HasPrefix(patch, []byte("BPS1")) {
+		return nil, ErrMalformed
+	}
+
+	footer := patch[len(patch)-12:]
+	if crc32.ChecksumIEEE(patch[:len(patch)-4]) != binary.LittleEndian.Uint32(footer[8:]) {
+		return nil, ErrChecksum
+	}
+	if crc32.ChecksumIEEE(source) != binary.LittleEndian.Uint32(footer[0:]) {
+		return nil, ErrChecksum
+	}
+
+	r := bytes.NewReader(patch[4 : len(patch)-12])
+
+	sourceSize, err := readBPSNumber(r)
+	if err != nil {
+		return nil, err
+	}
+	if sourceSize != uint64(len(source)) {
+		return nil, ErrMalformed
+	}
+
+	targetSize, err := readBPSNumber(r)
+	if err != nil {
+		return nil, err
+	}
+
+	metadataSize, err := readBPSNumber(r)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(int64(metadataSize), io.SeekCurrent); err != nil {
+		return nil, ErrMalformed
+	}
+
+	target := make([]byte, 0, targetSize)
+	sourceRel := 0
+	targetRel := 0
+	for r.Len() > 0 {
+		n, err := readBPSNumber(r)
+		if err != nil {
+			return nil, err
+		}
+		length := int(n>>2) + 1
+
+		switch n & 3 {
+		case bpsSourceRead:
+			if len(target)+length > len(source) {
+				return nil, ErrMalformed
+			}
+			target = append(target, source[len(target):len(target)+length]...)
+		case bpsTargetRead:
+			data := make([]byte, length)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, ErrMalformed
+			}
+			target = append(target, data...)
+		case bpsSourceCopy:
+			d, err := readBPSOffset(r)
+			if err != nil {
+				return nil, err
+			}
+			sourceRel += d
+			if sourceRel < 0 || sourceRel+length > len(source) {
+				return nil, ErrMalformed
+			}
+			target = append(target, source[sourceRel:sourceRel+length]...)
+			sourceRel += length
+		case bpsTargetCopy:
+			d, err := readBPSOffset(r)
+			if err != nil {
+				return nil, err
+			}
+			targetRel += d
+			if targetRel < 0 || targetRel >= len(target) {
+				return nil, ErrMalformed
+			}
+			// Target copies may overlap what they write, so copy a byte at a time.
+			for i := 0; i < length; i++ {
+				target = append(target, target[targetRel])
+				targetRel++
+			}
+		}
+	}
+
+	if uint64(len(target)) != targetSize {
+		return nil, ErrMalformed
+	}
+	if crc32.ChecksumIEEE(target) != binary.LittleEndian.Uint32(footer[4:]) {
+		return nil, ErrChecksum
+	}
+
+	return target, nil
+}
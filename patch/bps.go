@@ -0,0 +1,62 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+const (
+	bpsSourceRead = 0
+	bpsTargetRead = 1
+)
+
+func writeBPSNumber(w *bytes.Buffer, n uint64) {
+	for {
+		x := byte(n & 0x7F)
+		n >>= 7
+		if n == 0 {
+			w.WriteByte(0x80 | x)
+			return
+		}
+		w.WriteByte(x)
+		n--
+	}
+}
+
+// WriteBPS writes a BPS patch that turns source into target, using only source reads and literal target reads.
+func WriteBPS(w io.Writer, source []byte, target []byte) error {
+	var buf bytes.Buffer
+	buf.WriteString("BPS1")
+	writeBPSNumber(&buf, uint64(len(source)))
+	writeBPSNumber(&buf, uint64(len(target)))
+	writeBPSNumber(&buf, 0)
+
+	for i := 0; i < len(target); {
+		start := i
+		if i < len(source) && source[i] == target[i] {
+			for i < len(target) && i < len(source) && source[i] == target[i] {
+				i++
+			}
+			writeBPSNumber(&buf, uint64(i-start-1)<<2|bpsSourceRead)
+			continue
+		}
+
+		for i < len(target) && !(i < len(source) && source[i] == target[i]) {
+			i++
+		}
+		writeBPSNumber(&buf, uint64(i-start-1)<<2|bpsTargetRead)
+		buf.Write(target[start:i])
+	}
+
+	binary.Write(&buf, binary.LittleEndian, crc32.ChecksumIEEE(source))
+	binary.Write(&buf, binary.LittleEndian, crc32.ChecksumIEEE(target))
+	binary.Write(&buf, binary.LittleEndian, crc32.ChecksumIEEE(buf.Bytes()))
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package patch
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrIPSTooLarge = errors.New("patch: ips cannot address offsets past 16 MiB")
+
+const (
+	ipsMaxOffset     = 0xFFFFFF
+	ipsMaxRecordSize = 0xFFFF
+
+	// A record starting at this offset would be read as the "EOF" trailer.
+	ipsEOFOffset = 0x454F46
+)
+
+func writeIPSRecord(w io.Writer, offset int, data []byte) error {
+	if _, err := w.Write([]byte{
+		byte(offset >> 16), byte(offset >> 8), byte(offset),
+		byte(len(data) >> 8), byte(len(data)),
+	}); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// WriteIPS writes an IPS patch that turns source into target. target must not be shorter than source.
+func WriteIPS(w io.Writer, source []byte, target []byte) error {
+	if len(target) < len(source) {
+		return ErrShrinking
+	}
+
+	if _, err := io.WriteString(w, "PATCH"); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(target); {
+		if i < len(source) && source[i] == target[i] {
+			i++
+			continue
+		}
+
+		start := i
+		if start == ipsEOFOffset {
+			// Include the byte before so the record does not start at the trailer offset.
+			start--
+		}
+
+		if start > ipsMaxOffset {
+			return ErrIPSTooLarge
+		}
+
+		for i < len(target) && !(i < len(source) && source[i] == target[i]) && i-start < ipsMaxRecordSize {
+			i++
+		}
+
+		if err := writeIPSRecord(w, start, target[start:i]); err != nil {
+			return err
+		}
+	}
+
+	if _, err := io.WriteString(w, "EOF"); err != nil {
+		return err
+	}
+
+	return nil
+}
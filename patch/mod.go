@@ -0,0 +1,5 @@
+package patch
+
+import "errors"
+
+var ErrShrinking = errors.New("patch: target must not be shorter than source")
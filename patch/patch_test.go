@@ -0,0 +1,147 @@
+package patch
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func randomEdit(rng *rand.Rand, source []byte, grow int) []byte {
+	target := append(append([]byte(nil), source...), make([]byte, grow)...)
+	rng.Read(target[len(source):])
+	for i := 0; i < 20; i++ {
+		offset := rng.Intn(len(target))
+		n := rng.Intn(64) + 1
+		if offset+n > len(target) {
+			n = len(target) - offset
+		}
+		rng.Read(target[offset : offset+n])
+	}
+	return target
+}
+
+func TestIPSRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	source := make([]byte, 0x10000)
+	rng.Read(source)
+
+	for _, grow := range []int{0, 100} {
+		target := randomEdit(rng, source, grow)
+
+		var buf bytes.Buffer
+		if err := WriteIPS(&buf, source, target); err != nil {
+			t.Fatalf("WriteIPS() = %v", err)
+		}
+
+		got, err := ApplyIPS(source, buf.Bytes())
+		if err != nil {
+			t.Fatalf("ApplyIPS() = %v", err)
+		}
+		if !bytes.Equal(got, target) {
+			t.Errorf("ApplyIPS(WriteIPS()) with %d bytes grown does not give the target", grow)
+		}
+	}
+}
+
+func TestIPSEOFOffset(t *testing.T) {
+	source := make([]byte, ipsEOFOffset+0x10)
+	target := append([]byte(nil), source...)
+	target[ipsEOFOffset] = 1
+
+	var buf bytes.Buffer
+	if err := WriteIPS(&buf, source, target); err != nil {
+		t.Fatalf("WriteIPS() = %v", err)
+	}
+
+	if bytes.Contains(buf.Bytes()[5:len(buf.Bytes())-3], []byte("EOF")) {
+		t.Errorf("WriteIPS() wrote a record at the EOF offset")
+	}
+
+	got, err := ApplyIPS(source, buf.Bytes())
+	if err != nil {
+		t.Fatalf("ApplyIPS() = %v", err)
+	}
+	if !bytes.Equal(got, target) {
+		t.Errorf("ApplyIPS(WriteIPS()) does not give the target")
+	}
+}
+
+func TestIPSLongRecord(t *testing.T) {
+	source := make([]byte, 0x20000)
+	target := bytes.Repeat([]byte{0xAA}, len(source))
+
+	var buf bytes.Buffer
+	if err := WriteIPS(&buf, source, target); err != nil {
+		t.Fatalf("WriteIPS() = %v", err)
+	}
+
+	got, err := ApplyIPS(source, buf.Bytes())
+	if err != nil {
+		t.Fatalf("ApplyIPS() = %v", err)
+	}
+	if !bytes.Equal(got, target) {
+		t.Errorf("ApplyIPS(WriteIPS()) does not give the target")
+	}
+}
+
+func TestApplyIPSRLE(t *testing.T) {
+	patch := []byte("PATCH\x00\x00\x02\x00\x00\x00\x04\x7fEOF")
+	got, err := ApplyIPS([]byte{1, 2, 3}, patch)
+	if err != nil {
+		t.Fatalf("ApplyIPS() = %v", err)
+	}
+	if want := []byte{1, 2, 0x7f, 0x7f, 0x7f, 0x7f}; !bytes.Equal(got, want) {
+		t.Errorf("ApplyIPS() = %v, want %v", got, want)
+	}
+}
+
+func TestShrinking(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteIPS(&buf, []byte{1, 2}, []byte{1}); !errors.Is(err, ErrShrinking) {
+		t.Errorf("WriteIPS() = %v, want %v", err, ErrShrinking)
+	}
+}
+
+func TestBPSRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	source := make([]byte, 0x10000)
+	rng.Read(source)
+
+	for _, grow := range []int{0, 100} {
+		target := randomEdit(rng, source, grow)
+
+		var buf bytes.Buffer
+		if err := WriteBPS(&buf, source, target); err != nil {
+			t.Fatalf("WriteBPS() = %v", err)
+		}
+
+		got, err := ApplyBPS(source, buf.Bytes())
+		if err != nil {
+			t.Fatalf("ApplyBPS() = %v", err)
+		}
+		if !bytes.Equal(got, target) {
+			t.Errorf("ApplyBPS(WriteBPS()) with %d bytes grown does not give the target", grow)
+		}
+	}
+}
+
+func TestBPSChecksums(t *testing.T) {
+	source := []byte("source data")
+	target := []byte("target data!")
+
+	var buf bytes.Buffer
+	if err := WriteBPS(&buf, source, target); err != nil {
+		t.Fatalf("WriteBPS() = %v", err)
+	}
+
+	if _, err := ApplyBPS([]byte("other data!"), buf.Bytes()); !errors.Is(err, ErrChecksum) {
+		t.Errorf("ApplyBPS() to the wrong source = %v, want %v", err, ErrChecksum)
+	}
+
+	corrupt := append([]byte(nil), buf.Bytes()...)
+	corrupt[8] ^= 1
+	if _, err := ApplyBPS(source, corrupt); !errors.Is(err, ErrChecksum) {
+		t.Errorf("ApplyBPS() of a corrupt patch = %v, want %v", err, ErrChecksum)
+	}
+}
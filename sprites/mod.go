@@ -166,6 +166,10 @@ const (
 )
 
 type Frame struct {
+	// BPP is the depth of Tiles, either 4 or 8. 8bpp tiles index the whole palette, so OAM palette offsets don't apply
+	// to them.
+	BPP int
+
 	Palette    color.Palette
 	Delay      uint16
 	Action     FrameAction
@@ -173,13 +177,30 @@ type Frame struct {
 	OAMEntries []OAMEntry
 }
 
-func ReadTile(r io.Reader, bounds image.Rectangle) (*image.Paletted, error) {
-	pixels := make([]uint8, bounds.Dx()*bounds.Dy()/2)
+var ErrUnsupportedBPP = errors.New("sprites: unsupported bits per pixel")
+
+// TileSize returns how many bytes a tile of the given size and depth takes.
+func TileSize(bounds image.Rectangle, bpp int) int {
+	return bounds.Dx() * bounds.Dy() * bpp / 8
+}
+
+// ReadTileBPP reads a tile of either 4 or 8 bits per pixel. 4bpp pixels are packed two to a byte, low nibble first.
+func ReadTileBPP(r io.Reader, bounds image.Rectangle, bpp int) (*image.Paletted, error) {
+	if bpp != 4 && bpp != 8 {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedBPP, bpp)
+	}
+
+	pixels := make([]uint8, TileSize(bounds, bpp))
 	if _, err := io.ReadFull(r, pixels); err != nil {
 		return nil, err
 	}
 
 	pimg := image.NewPaletted(bounds, nil)
+	if bpp == 8 {
+		copy(pimg.Pix, pixels)
+		return pimg, nil
+	}
+
 	for i, p := range pixels {
 		pimg.Pix[i*2] = p & 0xF
 		pimg.Pix[i*2+1] = p >> 4
@@ -188,6 +209,36 @@ func ReadTile(r io.Reader, bounds image.Rectangle) (*image.Paletted, error) {
 	return pimg, nil
 }
 
+func ReadTile(r io.Reader, bounds image.Rectangle) (*image.Paletted, error) {
+	return ReadTileBPP(r, bounds, 4)
+}
+
+// WriteTileBPP writes img as pixels of either 4 or 8 bits, as read by ReadTileBPP.
+func WriteTileBPP(w io.Writer, img *image.Paletted, bpp int) error {
+	if bpp != 4 && bpp != 8 {
+		return fmt.Errorf("%w: %d", ErrUnsupportedBPP, bpp)
+	}
+
+	pixels := img.Pix
+	if bpp == 4 {
+		pixels = make([]uint8, len(img.Pix)/2)
+		for i := range pixels {
+			pixels[i] = img.Pix[i*2]&0xF | img.Pix[i*2+1]<<4
+		}
+	}
+
+	if _, err := w.Write(pixels); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// WriteTile writes img as 4bpp pixels, as read by ReadTile.
+func WriteTile(w io.Writer, img *image.Paletted) error {
+	return WriteTileBPP(w, img, 4)
+}
+
 func ReadPalette(r io.Reader) (color.Palette, error) {
 	var palette color.Palette
 
@@ -205,7 +256,13 @@ func ReadPalette(r io.Reader) (color.Palette, error) {
 	return palette, nil
 }
 
+// ReadFrame reads the frame next in r, with 4bpp tiles.
 func ReadFrame(r io.ReadSeeker, offset int64) (Frame, error) {
+	return ReadFrameBPP(r, offset, 4)
+}
+
+// ReadFrameBPP reads the frame next in r, with tiles of either 4 or 8 bits per pixel.
+func ReadFrameBPP(r io.ReadSeeker, offset int64, bpp int) (Frame, error) {
 	var fr Frame
 
 	var rawFr struct {
@@ -229,6 +286,7 @@ func ReadFrame(r io.ReadSeeker, offset int64) (Frame, error) {
 		r.Seek(retOffset, os.SEEK_SET)
 	}()
 
+	fr.BPP = bpp
 	fr.Delay = rawFr.Delay
 	fr.Action = FrameAction(rawFr.Action)
 
@@ -242,12 +300,12 @@ func ReadFrame(r io.ReadSeeker, offset int64) (Frame, error) {
 		return fr, fmt.Errorf("%w reading tiles at tile pointer 0x%08x", err, rawFr.TilesPtr)
 	}
 
-	numTiles := tilesByteSize / (8 * 8 / 2)
+	numTiles := tilesByteSize / uint32(TileSize(image.Rect(0, 0, 8, 8), bpp))
 
 	fr.Tiles = make([]*image.Paletted, numTiles)
 	for i := 0; i < int(numTiles); i++ {
 		var err error
-		fr.Tiles[i], err = ReadTile(r, image.Rect(0, 0, 8, 8))
+		fr.Tiles[i], err = ReadTileBPP(r, image.Rect(0, 0, 8, 8), bpp)
 		if err != nil {
 			return fr, fmt.Errorf("%w while reading tile %d at pointer 0x%08x", err, i, rawFr.TilesPtr)
 		}
@@ -283,8 +341,11 @@ func ReadFrame(r io.ReadSeeker, offset int64) (Frame, error) {
 			return fr, fmt.Errorf("%w while reading palbank %d at palette pointer 0x%08x", err, i, rawFr.PalPtr)
 		}
 
-		// Palette entry 0 is always transparent.
-		palette[0] = color.RGBA{}
+		// Color 0 of each palbank is transparent for 4bpp tiles. 8bpp tiles index the whole palette, so only its very first
+		// color is.
+		if bpp == 4 || i == 0 {
+			palette[0] = color.RGBA{}
+		}
 		fr.Palette = append(fr.Palette, palette...)
 	}
 
@@ -334,7 +395,9 @@ func (f *Frame) MakeImage() *image.Paletted {
 				tile := f.Tiles[oamEntry.TileIndex+j*oamEntry.WTiles+i]
 				tileCopy := image.NewPaletted(image.Rect(0, 0, 8, 8), nil)
 				for k := 0; k < len(tile.Pix); k++ {
-					if tile.Pix[k] != 0 {
+					if f.BPP == 8 {
+						tileCopy.Pix[k] = tile.Pix[k]
+					} else if tile.Pix[k] != 0 {
 						tileCopy.Pix[k] = tile.Pix[k] + uint8(16*oamEntry.PaletteOffset)
 					} else {
 						tileCopy.Pix[k] = 0
@@ -367,7 +430,13 @@ type Animation struct {
 	Frames []Frame
 }
 
+// ReadAnimation reads the animation whose pointer is next in r, with 4bpp tiles.
 func ReadAnimation(r io.ReadSeeker, offset int64) (Animation, error) {
+	return ReadAnimationBPP(r, offset, 4)
+}
+
+// ReadAnimationBPP reads the animation whose pointer is next in r, with tiles of either 4 or 8 bits per pixel.
+func ReadAnimationBPP(r io.ReadSeeker, offset int64, bpp int) (Animation, error) {
 	var anim Animation
 
 	var animPtr uint32
@@ -388,7 +457,7 @@ func ReadAnimation(r io.ReadSeeker, offset int64) (Animation, error) {
 	}
 
 	for i := 0; ; i++ {
-		frame, err := ReadFrame(r, offset)
+		frame, err := ReadFrameBPP(r, offset, bpp)
 		if err != nil {
 			return anim, fmt.Errorf("%w while reading frame %d at animation pointer 0x%08x", err, i, animPtr)
 		}
@@ -402,7 +471,13 @@ func ReadAnimation(r io.ReadSeeker, offset int64) (Animation, error) {
 	return anim, nil
 }
 
+// ReadAnimations reads the animation table next in r, with 4bpp tiles.
 func ReadAnimations(r io.ReadSeeker, offset int64) ([]Animation, error) {
+	return ReadAnimationsBPP(r, offset, 4)
+}
+
+// ReadAnimationsBPP reads the animation table next in r, with tiles of either 4 or 8 bits per pixel.
+func ReadAnimationsBPP(r io.ReadSeeker, offset int64, bpp int) ([]Animation, error) {
 	if _, err := io.CopyN(io.Discard, r, 3); err != nil {
 		return nil, fmt.Errorf("%w while discarding header", err)
 	}
@@ -414,7 +489,7 @@ func ReadAnimations(r io.ReadSeeker, offset int64) ([]Animation, error) {
 
 	anims := make([]Animation, n)
 	for i := 0; i < len(anims); i++ {
-		anim, err := ReadAnimation(r, offset)
+		anim, err := ReadAnimationBPP(r, offset, bpp)
 		if err != nil {
 			return nil, fmt.Errorf("%w while reading animation %d", err, i)
 		}
@@ -424,7 +499,14 @@ func ReadAnimations(r io.ReadSeeker, offset int64) ([]Animation, error) {
 	return anims, nil
 }
 
+// ReadNext reads the animations of the sprite whose pointer is next in r, with 4bpp tiles.
 func ReadNext(r io.ReadSeeker) ([]Animation, error) {
+	return ReadNextBPP(r, 4)
+}
+
+// ReadNextBPP reads the animations of the sprite whose pointer is next in r, with tiles of either 4 or 8 bits per
+// pixel.
+func ReadNextBPP(r io.ReadSeeker, bpp int) ([]Animation, error) {
 	var animPtr uint32
 	if err := binary.Read(r, binary.LittleEndian, &animPtr); err != nil {
 		return nil, fmt.Errorf("%w while reading sprite pointer 0x%08x", err, animPtr)
@@ -462,7 +544,7 @@ func ReadNext(r io.ReadSeeker) ([]Animation, error) {
 		return nil, fmt.Errorf("%w while seeking sprite pointer 0x%08x", err, animPtr)
 	}
 
-	anims, err := ReadAnimations(animR, int64(realPtr))
+	anims, err := ReadAnimationsBPP(animR, int64(realPtr), bpp)
 	if err != nil {
 		return nil, fmt.Errorf("%w while reading sprite at sprite pointer 0x%08x", err, animPtr)
 	}
@@ -0,0 +1,150 @@
+package sprites
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+	"testing"
+
+	"github.com/murkland/gbarom/bgr555"
+)
+
+func TestTileRoundTrip(t *testing.T) {
+	for _, bpp := range []int{4, 8} {
+		for _, bounds := range []image.Rectangle{image.Rect(0, 0, 8, 8), image.Rect(0, 0, 16, 8)} {
+			img := image.NewPaletted(bounds, nil)
+			for i := range img.Pix {
+				img.Pix[i] = uint8(i % (1 << bpp))
+			}
+
+			var buf bytes.Buffer
+			if err := WriteTileBPP(&buf, img, bpp); err != nil {
+				t.Fatalf("%dbpp %v: WriteTileBPP() error = %v", bpp, bounds, err)
+			}
+
+			if got, want := buf.Len(), TileSize(bounds, bpp); got != want {
+				t.Errorf("%dbpp %v: WriteTileBPP() wrote %d bytes, want %d", bpp, bounds, got, want)
+			}
+
+			got, err := ReadTileBPP(&buf, bounds, bpp)
+			if err != nil {
+				t.Fatalf("%dbpp %v: ReadTileBPP() error = %v", bpp, bounds, err)
+			}
+
+			if !bytes.Equal(got.Pix, img.Pix) {
+				t.Errorf("%dbpp %v: ReadTileBPP() = %v, want %v", bpp, bounds, got.Pix, img.Pix)
+			}
+		}
+	}
+}
+
+func TestTileUnsupportedBPP(t *testing.T) {
+	if _, err := ReadTileBPP(bytes.NewReader(make([]byte, 64)), image.Rect(0, 0, 8, 8), 2); err == nil {
+		t.Errorf("ReadTileBPP() at 2bpp succeeded, want error")
+	}
+	if err := WriteTileBPP(&bytes.Buffer{}, image.NewPaletted(image.Rect(0, 0, 8, 8), nil), 2); err == nil {
+		t.Errorf("WriteTileBPP() at 2bpp succeeded, want error")
+	}
+}
+
+// testFrame returns a frame with no tiles or OAM entries and two palbanks of white, to be read from offset 0.
+func testFrame() []byte {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 4))
+	binary.Write(&buf, binary.LittleEndian, struct {
+		TilesPtr  uint32
+		PalPtr    uint32
+		JunkPtr   uint32
+		OAMPtrPtr uint32
+		Delay     uint16
+		Action    uint16
+	}{20, 24, 0, 96, 1, uint16(FrameActionStop)})
+
+	// Tiles.
+	binary.Write(&buf, binary.LittleEndian, uint32(0))
+
+	// Palette.
+	binary.Write(&buf, binary.LittleEndian, uint32(2*16*2))
+	for i := 0; i < 2*16; i++ {
+		binary.Write(&buf, binary.LittleEndian, uint16(0x7FFF))
+	}
+	binary.Write(&buf, binary.LittleEndian, uint32(4))
+
+	// OAM.
+	binary.Write(&buf, binary.LittleEndian, uint32(4))
+	buf.Write([]byte{0xFF, 0, 0, 0, 0})
+
+	return buf.Bytes()
+}
+
+func TestReadFramePalette(t *testing.T) {
+	white := bgr555.ToRGBA(0x7FFF)
+
+	for _, tc := range []struct {
+		bpp  int
+		want color.Color
+	}{
+		{4, color.RGBA{}},
+		{8, white},
+	} {
+		r := bytes.NewReader(testFrame())
+		r.Seek(4, io.SeekStart)
+
+		fr, err := ReadFrameBPP(r, 0, tc.bpp)
+		if err != nil {
+			t.Fatalf("%dbpp: ReadFrameBPP() error = %v", tc.bpp, err)
+		}
+
+		if len(fr.Palette) != 32 {
+			t.Fatalf("%dbpp: ReadFrameBPP() read %d colors, want 32", tc.bpp, len(fr.Palette))
+		}
+
+		if fr.Palette[0] != (color.RGBA{}) {
+			t.Errorf("%dbpp: color 0 = %v, want transparent", tc.bpp, fr.Palette[0])
+		}
+		if fr.Palette[16] != tc.want {
+			t.Errorf("%dbpp: color 16 = %v, want %v", tc.bpp, fr.Palette[16], tc.want)
+		}
+		if fr.Palette[17] != white {
+			t.Errorf("%dbpp: color 17 = %v, want %v", tc.bpp, fr.Palette[17], white)
+		}
+	}
+}
+
+func TestMakeImagePaletteIndexing(t *testing.T) {
+	palette := make(color.Palette, 256)
+	for i := range palette {
+		palette[i] = color.Gray{uint8(i)}
+	}
+
+	for _, tc := range []struct {
+		bpp  int
+		pix  uint8
+		want uint8
+	}{
+		// 4bpp tiles are offset into the OAM entry's palbank.
+		{4, 5, 3*16 + 5},
+		// 8bpp tiles index the whole palette, whatever the OAM entry says.
+		{8, 200, 200},
+	} {
+		tile := image.NewPaletted(image.Rect(0, 0, 8, 8), nil)
+		tile.Pix[0] = tc.pix
+
+		fr := Frame{
+			BPP:        tc.bpp,
+			Palette:    palette,
+			Tiles:      []*image.Paletted{tile},
+			OAMEntries: []OAMEntry{{TileIndex: 0, WTiles: 1, HTiles: 1, PaletteOffset: 3}},
+		}
+
+		img := fr.MakeImage()
+		if got := img.ColorIndexAt(img.Rect.Dx()/2, img.Rect.Dy()/2); got != tc.want {
+			t.Errorf("%dbpp: pixel = %d, want %d", tc.bpp, got, tc.want)
+		}
+		if got := img.ColorIndexAt(img.Rect.Dx()/2+1, img.Rect.Dy()/2); got != 0 {
+			t.Errorf("%dbpp: transparent pixel = %d, want 0", tc.bpp, got)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package text
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FormatArchive writes an archive in its readable form: an @entries line listing the script each pointer table entry
+// refers to, then each script after an @script line.
+func FormatArchive(a *Archive, d Dialect) string {
+	var sb strings.Builder
+
+	sb.WriteString("@entries")
+	for _, scriptIdx := range a.Entries {
+		fmt.Fprintf(&sb, " %d", scriptIdx)
+	}
+	sb.WriteString("\n")
+
+	for i, script := range a.Scripts {
+		fmt.Fprintf(&sb, "@script %d\n", i)
+		sb.WriteString(script.Format(d))
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// ParseArchive parses the readable form of an archive written by FormatArchive.
+func ParseArchive(s string, d Dialect) (*Archive, error) {
+	const entriesHeader = "@entries"
+	const scriptHeader = "\n@script "
+
+	if !strings.HasPrefix(s, entriesHeader) {
+		return nil, fmt.Errorf("%w: missing %s", ErrSyntax, entriesHeader)
+	}
+
+	a := &Archive{}
+
+	// Scripts end with the newline before the next header, which isn't part of them.
+	parts := strings.Split(strings.TrimSuffix(s, "\n"), scriptHeader)
+	for _, field := range strings.Fields(parts[0][len(entriesHeader):]) {
+		scriptIdx, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad entry %s", ErrSyntax, field)
+		}
+		a.Entries = append(a.Entries, scriptIdx)
+	}
+
+	for i, part := range parts[1:] {
+		header, body, _ := strings.Cut(part, "\n")
+		if header != strconv.Itoa(i) {
+			return nil, fmt.Errorf("%w: expected script %d, got %s", ErrSyntax, i, header)
+		}
+
+		script, err := Parse(body, d)
+		if err != nil {
+			return nil, fmt.Errorf("%w while parsing script %d", err, i)
+		}
+		a.Scripts = append(a.Scripts, script)
+	}
+
+	return a, nil
+}
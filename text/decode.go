@@ -0,0 +1,199 @@
+package text
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/murkland/gbarom/lz77"
+)
+
+var ErrInvalidArchive = errors.New("text: invalid archive")
+
+// uniqueRunes returns which charmap entries can be decoded to text and encoded back to the same index.
+func uniqueRunes(charmap []rune) []bool {
+	counts := map[rune]int{}
+	for _, c := range charmap {
+		counts[c]++
+	}
+
+	unique := make([]bool, len(charmap))
+	for i, c := range charmap {
+		unique[i] = counts[c] == 1
+	}
+	return unique
+}
+
+// Decode decodes a script from data. If untilEnd is set, decoding stops after the first end command, otherwise all of
+// data is decoded. It returns the script and the number of bytes decoded.
+//
+// Characters that aren't in the charmap or that share a rune with another entry, and bytes that don't start a known
+// command, decode as raw bytes so the script can always be encoded back to the same bytes.
+func Decode(data []byte, charmap []rune, d Dialect, untilEnd bool) (Script, int) {
+	unique := uniqueRunes(charmap)
+
+	var script Script
+	var text []rune
+
+	flushText := func() {
+		if len(text) > 0 {
+			script = append(script, Token{Kind: TokenText, Text: string(text)})
+			text = nil
+		}
+	}
+
+	i := 0
+	for i < len(data) {
+		b := data[i]
+
+		if b < d.ExtPrefix {
+			if int(b) < len(charmap) && unique[b] {
+				text = append(text, charmap[b])
+				i++
+				continue
+			}
+		} else if b == d.ExtPrefix {
+			if i+1 < len(data) {
+				idx := int(d.ExtPrefix) + int(data[i+1])
+				if idx < len(charmap) && unique[idx] {
+					text = append(text, charmap[idx])
+					i += 2
+					continue
+				}
+			}
+		} else if cmd, ok := d.Commands[b]; ok && i+1+cmd.NumParams <= len(data) {
+			flushText()
+			params := make([]uint8, cmd.NumParams)
+			copy(params, data[i+1:i+1+cmd.NumParams])
+			script = append(script, Token{Kind: TokenCommand, Opcode: b, Params: params})
+			i += 1 + cmd.NumParams
+
+			if untilEnd && b == d.End {
+				break
+			}
+			continue
+		}
+
+		flushText()
+		script = append(script, Token{Kind: TokenRaw, Opcode: b})
+		i++
+	}
+	flushText()
+
+	return script, i
+}
+
+type Archive struct {
+	Scripts []Script
+
+	// Entries holds the index into Scripts of each pointer table entry. Scripts not referenced by any entry are kept
+	// so the archive can be rebuilt byte for byte.
+	Entries []int
+
+	// Size is how many bytes the archive took up where it was read from, compressed if it was compressed.
+	Size int
+}
+
+// DecodeArchive decodes a text archive: a table of 16-bit offsets relative to the start of the archive, followed by
+// the scripts. The number of entries is the first offset divided by two. Each script runs up to the start of the next
+// one, and the last one up to its end command.
+func DecodeArchive(data []byte, charmap []rune, d Dialect) (*Archive, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("%w: too short for pointer table", ErrInvalidArchive)
+	}
+
+	tableSize := int(binary.LittleEndian.Uint16(data))
+	if tableSize == 0 || tableSize%2 != 0 || tableSize > len(data) {
+		return nil, fmt.Errorf("%w: bad pointer table size %d", ErrInvalidArchive, tableSize)
+	}
+
+	offsets := make([]int, tableSize/2)
+	starts := map[int]struct{}{tableSize: {}}
+	for i := range offsets {
+		offsets[i] = int(binary.LittleEndian.Uint16(data[i*2:]))
+		if offsets[i] < tableSize || offsets[i] > len(data) {
+			return nil, fmt.Errorf("%w: entry %d points to 0x%04x", ErrInvalidArchive, i, offsets[i])
+		}
+		starts[offsets[i]] = struct{}{}
+	}
+
+	sortedStarts := make([]int, 0, len(starts))
+	for start := range starts {
+		sortedStarts = append(sortedStarts, start)
+	}
+	sort.Ints(sortedStarts)
+
+	archive := &Archive{}
+	scriptIndexes := map[int]int{}
+	for i, start := range sortedStarts {
+		var script Script
+		if i+1 < len(sortedStarts) {
+			script, _ = Decode(data[start:sortedStarts[i+1]], charmap, d, false)
+		} else {
+			var n int
+			script, n = Decode(data[start:], charmap, d, true)
+			archive.Size = start + n
+		}
+
+		scriptIndexes[start] = len(archive.Scripts)
+		archive.Scripts = append(archive.Scripts, script)
+	}
+
+	archive.Entries = make([]int, len(offsets))
+	for i, offset := range offsets {
+		archive.Entries[i] = scriptIndexes[offset]
+	}
+
+	return archive, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += n
+	return n, err
+}
+
+// ReadArchive reads the text archive at ptr. Pointers with the high bit set point to LZ77 compressed archives.
+func ReadArchive(r io.ReadSeeker, ptr uint32, charmap []rune, d Dialect) (*Archive, error) {
+	if _, err := r.Seek(int64(ptr & ^uint32(0x88000000)), os.SEEK_SET); err != nil {
+		return nil, fmt.Errorf("%w while seeking to text archive pointer 0x%08x", err, ptr)
+	}
+
+	var data []byte
+	compressedSize := 0
+	if ptr&0x80000000 == 0x80000000 {
+		cr := &countingReader{r: r}
+
+		var err error
+		data, err = lz77.Decompress(cr)
+		if err != nil {
+			return nil, fmt.Errorf("%w while decompressing text archive pointer 0x%08x", err, ptr)
+		}
+		compressedSize = cr.n
+	} else {
+		var err error
+		data, err = io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w while reading text archive pointer 0x%08x", err, ptr)
+		}
+	}
+
+	archive, err := DecodeArchive(data, charmap, d)
+	if err != nil {
+		return nil, fmt.Errorf("%w while decoding text archive pointer 0x%08x", err, ptr)
+	}
+
+	if compressedSize > 0 {
+		archive.Size = compressedSize
+	}
+
+	return archive, nil
+}
@@ -0,0 +1,139 @@
+package text
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testCharmap maps every index to a distinct rune, with a few readable ones and a duplicated pair at 0x10 and 0x11.
+func testCharmap() []rune {
+	charmap := make([]rune, 0x1F0)
+	for i := range charmap {
+		charmap[i] = rune(0x4E00 + i)
+	}
+	copy(charmap, []rune("abc"))
+	charmap[0x10] = '?'
+	charmap[0x11] = '?'
+	charmap[0xE5] = 'X'
+	return charmap
+}
+
+func TestDecode(t *testing.T) {
+	charmap := testCharmap()
+
+	for _, tc := range []struct {
+		name     string
+		data     []byte
+		untilEnd bool
+		want     Script
+		wantN    int
+	}{
+		{
+			name:  "plain characters",
+			data:  []byte{0x00, 0x01, 0x02},
+			want:  Script{{Kind: TokenText, Text: "abc"}},
+			wantN: 3,
+		},
+		{
+			name:  "extended character",
+			data:  []byte{0x00, 0xE4, 0x01, 0x02},
+			want:  Script{{Kind: TokenText, Text: "aXc"}},
+			wantN: 4,
+		},
+		{
+			name: "commands with arguments",
+			data: []byte{0x00, 0xEA, 0x10, 0xEC, 0x01, 0x02, 0x03, 0xE9, 0x01},
+			want: Script{
+				{Kind: TokenText, Text: "a"},
+				{Kind: TokenCommand, Opcode: 0xEA, Params: []uint8{0x10}},
+				{Kind: TokenCommand, Opcode: 0xEC, Params: []uint8{0x01, 0x02, 0x03}},
+				{Kind: TokenCommand, Opcode: 0xE9, Params: []uint8{}},
+				{Kind: TokenText, Text: "b"},
+			},
+			wantN: 9,
+		},
+		{
+			name:     "until end",
+			data:     []byte{0x00, 0xE6, 0x01},
+			untilEnd: true,
+			want: Script{
+				{Kind: TokenText, Text: "a"},
+				{Kind: TokenCommand, Opcode: 0xE6, Params: []uint8{}},
+			},
+			wantN: 2,
+		},
+		{
+			name: "raw bytes",
+			data: []byte{0x10, 0x00, 0xFF, 0xEA},
+			want: Script{
+				{Kind: TokenRaw, Opcode: 0x10},
+				{Kind: TokenText, Text: "a"},
+				{Kind: TokenRaw, Opcode: 0xFF},
+				{Kind: TokenRaw, Opcode: 0xEA},
+			},
+			wantN: 4,
+		},
+		{
+			name: "extended prefix without a character",
+			data: []byte{0x01, 0xE4},
+			want: Script{
+				{Kind: TokenText, Text: "b"},
+				{Kind: TokenRaw, Opcode: 0xE4},
+			},
+			wantN: 2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, n := Decode(tc.data, charmap, bn6Dialect, tc.untilEnd)
+			if !reflect.DeepEqual(got, tc.want) || n != tc.wantN {
+				t.Errorf("Decode(% x) = %+v, %d, want %+v, %d", tc.data, got, n, tc.want, tc.wantN)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	script, _ := Decode([]byte{0x00, 0xEA, 0x10, 0xE9, 0x10, 0x01}, testCharmap(), bn6Dialect, false)
+	if got, want := script.Format(bn6Dialect), "a[wait 16]\n[$10]b"; got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestDecodeArchive(t *testing.T) {
+	data := []byte{
+		0x06, 0x00, 0x09, 0x00, 0x06, 0x00,
+		0x00, 0xE6, 0x00,
+		0x01, 0xE6,
+	}
+
+	archive, err := DecodeArchive(data, testCharmap(), bn6Dialect)
+	if err != nil {
+		t.Fatalf("DecodeArchive() = %v", err)
+	}
+
+	if want := []int{0, 1, 0}; !reflect.DeepEqual(archive.Entries, want) {
+		t.Errorf("Entries = %v, want %v", archive.Entries, want)
+	}
+
+	var got []string
+	for _, script := range archive.Scripts {
+		got = append(got, script.Format(bn6Dialect))
+	}
+	if want := []string{"a[end]a", "b[end]"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Scripts = %q, want %q", got, want)
+	}
+
+	if archive.Size != len(data) {
+		t.Errorf("Size = %d, want %d", archive.Size, len(data))
+	}
+}
+
+func TestFindDialectCopy(t *testing.T) {
+	d := FindDialect("BR6E")
+	d.Commands[0xEA] = Command{"changed", 0}
+	d.End = 0
+
+	if d := FindDialect("BR6E"); d.Commands[0xEA].Name != "wait" || d.End != 0xE6 {
+		t.Errorf("FindDialect() returned a dialect changed by an earlier caller")
+	}
+}
@@ -0,0 +1,185 @@
+package text
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrUnencodable = errors.New("text: unencodable")
+
+// Encode encodes a script with a charmap. Runes that appear in the charmap more than once are encoded as their first
+// entry.
+func Encode(s Script, charmap []rune, d Dialect) ([]byte, error) {
+	runeIndexes := map[rune]int{}
+	for i := len(charmap) - 1; i >= 0; i-- {
+		runeIndexes[charmap[i]] = i
+	}
+
+	var out []byte
+	for _, tok := range s {
+		switch tok.Kind {
+		case TokenText:
+			for _, c := range tok.Text {
+				idx, ok := runeIndexes[c]
+				if !ok {
+					return nil, fmt.Errorf("%w: %q not in charmap", ErrUnencodable, c)
+				}
+
+				if idx < int(d.ExtPrefix) {
+					out = append(out, uint8(idx))
+					continue
+				}
+
+				if idx-int(d.ExtPrefix) > 0xFF {
+					return nil, fmt.Errorf("%w: %q is past the extended character range", ErrUnencodable, c)
+				}
+				out = append(out, d.ExtPrefix, uint8(idx-int(d.ExtPrefix)))
+			}
+		case TokenCommand:
+			cmd, ok := d.Commands[tok.Opcode]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown command 0x%02x", ErrUnencodable, tok.Opcode)
+			}
+
+			if len(tok.Params) != cmd.NumParams {
+				return nil, fmt.Errorf("%w: %s takes %d params, got %d", ErrUnencodable, cmd.Name, cmd.NumParams, len(tok.Params))
+			}
+
+			out = append(out, tok.Opcode)
+			out = append(out, tok.Params...)
+		case TokenRaw:
+			out = append(out, tok.Opcode)
+		}
+	}
+
+	return out, nil
+}
+
+var ErrSyntax = errors.New("text: syntax error")
+
+// Parse parses the readable form of a script written by Script.Format.
+func Parse(s string, d Dialect) (Script, error) {
+	var script Script
+	var text strings.Builder
+
+	flushText := func() {
+		if text.Len() > 0 {
+			script = append(script, Token{Kind: TokenText, Text: text.String()})
+			text.Reset()
+		}
+	}
+
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		switch rs[i] {
+		case '\\':
+			i++
+			if i >= len(rs) {
+				return nil, fmt.Errorf("%w: trailing backslash", ErrSyntax)
+			}
+
+			switch rs[i] {
+			case '\\', '[', '@':
+				text.WriteRune(rs[i])
+			case 'n':
+				text.WriteRune('\n')
+			default:
+				return nil, fmt.Errorf("%w: unknown escape \\%c", ErrSyntax, rs[i])
+			}
+		case '\n':
+			flushText()
+			script = append(script, Token{Kind: TokenCommand, Opcode: d.Newline, Params: []uint8{}})
+		case '[':
+			end := i + 1
+			for end < len(rs) && rs[end] != ']' {
+				end++
+			}
+			if end >= len(rs) {
+				return nil, fmt.Errorf("%w: unterminated [", ErrSyntax)
+			}
+
+			fields := strings.Fields(string(rs[i+1 : end]))
+			i = end
+
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("%w: empty []", ErrSyntax)
+			}
+
+			flushText()
+
+			if strings.HasPrefix(fields[0], "$") {
+				if len(fields) != 1 {
+					return nil, fmt.Errorf("%w: raw byte %s takes no params", ErrSyntax, fields[0])
+				}
+
+				b, err := strconv.ParseUint(fields[0][1:], 16, 8)
+				if err != nil {
+					return nil, fmt.Errorf("%w: bad raw byte %s", ErrSyntax, fields[0])
+				}
+
+				script = append(script, Token{Kind: TokenRaw, Opcode: uint8(b)})
+				continue
+			}
+
+			opcode, cmd, ok := d.commandByName(fields[0])
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown command %s", ErrSyntax, fields[0])
+			}
+
+			if len(fields)-1 != cmd.NumParams {
+				return nil, fmt.Errorf("%w: %s takes %d params, got %d", ErrSyntax, cmd.Name, cmd.NumParams, len(fields)-1)
+			}
+
+			params := make([]uint8, cmd.NumParams)
+			for j, field := range fields[1:] {
+				p, err := strconv.ParseUint(field, 0, 8)
+				if err != nil {
+					return nil, fmt.Errorf("%w: bad param %s to %s", ErrSyntax, field, cmd.Name)
+				}
+				params[j] = uint8(p)
+			}
+
+			script = append(script, Token{Kind: TokenCommand, Opcode: opcode, Params: params})
+		default:
+			text.WriteRune(rs[i])
+		}
+	}
+	flushText()
+
+	return script, nil
+}
+
+// EncodeArchive encodes an archive as a pointer table followed by its scripts in order. Encoding an archive decoded
+// with DecodeArchive without changes gives back the same bytes.
+func EncodeArchive(a *Archive, charmap []rune, d Dialect) ([]byte, error) {
+	tableSize := len(a.Entries) * 2
+
+	out := make([]byte, tableSize)
+	scriptOffsets := make([]int, len(a.Scripts))
+	for i, script := range a.Scripts {
+		scriptOffsets[i] = len(out)
+
+		buf, err := Encode(script, charmap, d)
+		if err != nil {
+			return nil, fmt.Errorf("%w while encoding script %d", err, i)
+		}
+		out = append(out, buf...)
+	}
+
+	for i, scriptIdx := range a.Entries {
+		if scriptIdx < 0 || scriptIdx >= len(scriptOffsets) {
+			return nil, fmt.Errorf("%w: entry %d refers to missing script %d", ErrUnencodable, i, scriptIdx)
+		}
+
+		offset := scriptOffsets[scriptIdx]
+		if offset > 0xFFFF {
+			return nil, fmt.Errorf("%w: entry %d is past the 16-bit offset range", ErrUnencodable, i)
+		}
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(offset))
+	}
+
+	return out, nil
+}
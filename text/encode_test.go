@@ -0,0 +1,149 @@
+package text
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/murkland/gbarom/lz77"
+)
+
+// escapeCharmap is testCharmap with the characters Format escapes in it.
+func escapeCharmap() []rune {
+	charmap := testCharmap()
+	copy(charmap[3:], []rune(`[\@]`))
+	return charmap
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	charmap := escapeCharmap()
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 200; i++ {
+		data := make([]byte, rng.Intn(64))
+		rng.Read(data)
+
+		script, n := Decode(data, charmap, bn6Dialect, false)
+		if n != len(data) {
+			t.Fatalf("Decode(% x) decoded %d bytes, want %d", data, n, len(data))
+		}
+
+		got, err := Encode(script, charmap, bn6Dialect)
+		if err != nil {
+			t.Fatalf("Encode(Decode(% x)) = %v", data, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("Encode(Decode(% x)) = % x", data, got)
+		}
+
+		parsed, err := Parse(script.Format(bn6Dialect), bn6Dialect)
+		if err != nil {
+			t.Fatalf("Parse(%q) = %v", script.Format(bn6Dialect), err)
+		}
+
+		got, err = Encode(parsed, charmap, bn6Dialect)
+		if err != nil {
+			t.Fatalf("Encode(Parse(%q)) = %v", script.Format(bn6Dialect), err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("Encode(Parse(Format(Decode(% x)))) = % x", data, got)
+		}
+	}
+}
+
+// randomArchive builds an archive of n scripts with entries pointing at them in a shuffled order,
+// some more than once.
+// Only the last script has to end with an end command, so the others are fully random.
+func randomArchive(rng *rand.Rand, n int) []byte {
+	var scripts [][]byte
+	for i := 0; i < n; i++ {
+		script := make([]byte, rng.Intn(32)+1)
+		rng.Read(script)
+		if i == n-1 {
+			for j := range script {
+				script[j] %= bn6Dialect.ExtPrefix
+			}
+			script = append(script, bn6Dialect.End)
+		}
+		scripts = append(scripts, script)
+	}
+
+	numEntries := n + 2
+	data := make([]byte, numEntries*2)
+	offsets := make([]int, n)
+	for i, script := range scripts {
+		offsets[i] = len(data)
+		data = append(data, script...)
+	}
+
+	// The first entry has to point to the first script, as the table size is read from it.
+	entries := append([]int{0}, rng.Perm(n)...)
+	entries = append(entries, rng.Intn(n))
+	for i, scriptIdx := range entries {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(offsets[scriptIdx]))
+	}
+	return data
+}
+
+func TestEncodeArchiveRoundTrip(t *testing.T) {
+	charmap := escapeCharmap()
+	rng := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 50; i++ {
+		data := randomArchive(rng, rng.Intn(8)+1)
+
+		a, err := DecodeArchive(data, charmap, bn6Dialect)
+		if err != nil {
+			t.Fatalf("DecodeArchive(% x) = %v", data, err)
+		}
+
+		got, err := EncodeArchive(a, charmap, bn6Dialect)
+		if err != nil {
+			t.Fatalf("EncodeArchive() = %v", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("EncodeArchive(DecodeArchive(% x)) = % x", data, got)
+		}
+
+		parsed, err := ParseArchive(FormatArchive(a, bn6Dialect), bn6Dialect)
+		if err != nil {
+			t.Fatalf("ParseArchive() = %v", err)
+		}
+
+		got, err = EncodeArchive(parsed, charmap, bn6Dialect)
+		if err != nil {
+			t.Fatalf("EncodeArchive(ParseArchive()) = %v", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("EncodeArchive(ParseArchive(FormatArchive(DecodeArchive(% x)))) = % x", data, got)
+		}
+	}
+}
+
+func TestCompressLZ77(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+
+	random := make([]byte, 0x1000)
+	rng.Read(random)
+
+	repetitive := make([]byte, 0x3000)
+	for i := range repetitive {
+		repetitive[i] = byte(i/7) % 5
+	}
+
+	for _, data := range [][]byte{nil, {0x42}, bytes.Repeat([]byte{0xAA}, 100), random, repetitive} {
+		compressed := compressLZ77(data)
+		if len(compressed)%4 != 0 {
+			t.Errorf("compressLZ77() of %d bytes is %d bytes, not a multiple of 4", len(data), len(compressed))
+		}
+
+		got, err := lz77.Decompress(bytes.NewReader(compressed))
+		if err != nil {
+			t.Fatalf("lz77.Decompress(compressLZ77()) of %d bytes = %v", len(data), err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("lz77.Decompress(compressLZ77()) of %d bytes does not give the data back", len(data))
+		}
+	}
+}
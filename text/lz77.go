@@ -0,0 +1,60 @@
+package text
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// compressLZ77 compresses data in the format read by lz77.Decompress. Back references are kept at least 2 bytes
+// back so the output is also safe to decompress straight into VRAM.
+func compressLZ77(data []byte) []byte {
+	const (
+		minMatch   = 3
+		maxMatch   = 18
+		minDisp    = 2
+		maxDisp    = 0x1000
+		headerType = 0x10
+	)
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, uint32(headerType|len(data)<<8))
+
+	for i := 0; i < len(data); {
+		flagsPos := out.Len()
+		out.WriteByte(0)
+
+		var flags uint8
+		for k := 0; k < 8 && i < len(data); k++ {
+			bestLen := 0
+			bestDisp := 0
+			for disp := minDisp; disp <= maxDisp && disp <= i; disp++ {
+				n := 0
+				for n < maxMatch && i+n < len(data) && data[i+n] == data[i-disp+n] {
+					n++
+				}
+				if n > bestLen {
+					bestLen = n
+					bestDisp = disp
+				}
+			}
+
+			if bestLen < minMatch {
+				out.WriteByte(data[i])
+				i++
+				continue
+			}
+
+			flags |= 0x80 >> k
+			binary.Write(&out, binary.BigEndian, uint16((bestLen-minMatch)<<12|(bestDisp-1)))
+			i += bestLen
+		}
+
+		out.Bytes()[flagsPos] = flags
+	}
+
+	for out.Len()%4 != 0 {
+		out.WriteByte(0)
+	}
+
+	return out.Bytes()
+}
@@ -0,0 +1,127 @@
+package text
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Command struct {
+	Name      string
+	NumParams int
+}
+
+// Dialect describes how a game encodes message scripts.
+type Dialect struct {
+	// Bytes below ExtPrefix index the charmap directly. ExtPrefix followed by another byte b indexes the charmap at
+	// ExtPrefix+b.
+	ExtPrefix uint8
+
+	// End terminates a script.
+	End uint8
+
+	// Newline is written as a line break in the script representation.
+	Newline uint8
+
+	Commands map[uint8]Command
+}
+
+// clone returns a copy of d that shares nothing with it.
+func (d Dialect) clone() Dialect {
+	commands := make(map[uint8]Command, len(d.Commands))
+	for opcode, cmd := range d.Commands {
+		commands[opcode] = cmd
+	}
+	d.Commands = commands
+	return d
+}
+
+func (d Dialect) commandByName(name string) (uint8, Command, bool) {
+	for opcode, cmd := range d.Commands {
+		if cmd.Name == name {
+			return opcode, cmd, true
+		}
+	}
+	return 0, Command{}, false
+}
+
+// bn6Dialect is the script dialect of Battle Network 6. Command names and parameter counts follow community documentation and
+// have only been checked against a handful of scripts: any byte not listed here decodes as a raw byte, so a missing or
+// wrong entry makes a script harder to read but never lossy.
+var bn6Dialect = Dialect{
+	ExtPrefix: 0xE4,
+	End:       0xE6,
+	Newline:   0xE9,
+	Commands: map[uint8]Command{
+		0xE6: {"end", 0},
+		0xE7: {"keyWait", 1},
+		0xE8: {"clearMsg", 0},
+		0xE9: {"newline", 0},
+		0xEA: {"wait", 1},
+		0xEC: {"option", 3},
+		0xF2: {"print", 2},
+		0xF3: {"textColor", 1},
+	},
+}
+
+type TokenKind int
+
+const (
+	TokenText TokenKind = iota
+	TokenCommand
+	TokenRaw
+)
+
+type Token struct {
+	Kind TokenKind
+
+	// Text is set for TokenText.
+	Text string
+
+	// Opcode and Params are set for TokenCommand. Opcode alone is set for TokenRaw.
+	Opcode uint8
+	Params []uint8
+}
+
+type Script []Token
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `[`, `\[`, `@`, `\@`, "\n", `\n`).Replace(s)
+}
+
+// Format writes a script in its readable form: text as is, commands as [name param...], bytes that don't decode as
+// [$xx] and the newline command as a line break. Backslashes, brackets, at signs and newlines in text are escaped with
+// a backslash.
+func (s Script) Format(d Dialect) string {
+	var sb strings.Builder
+	for _, tok := range s {
+		switch tok.Kind {
+		case TokenText:
+			sb.WriteString(escape(tok.Text))
+		case TokenCommand:
+			if tok.Opcode == d.Newline && len(tok.Params) == 0 {
+				sb.WriteString("\n")
+				continue
+			}
+
+			sb.WriteString("[")
+			sb.WriteString(d.Commands[tok.Opcode].Name)
+			for _, p := range tok.Params {
+				fmt.Fprintf(&sb, " %d", p)
+			}
+			sb.WriteString("]")
+		case TokenRaw:
+			fmt.Fprintf(&sb, "[$%02x]", tok.Opcode)
+		}
+	}
+	return sb.String()
+}
+
+// FindDialect returns a copy of the script dialect of a game, which the caller is free to modify.
+func FindDialect(romID string) *Dialect {
+	switch romID {
+	case "BR6E", "BR6P", "BR5E", "BR5P", "BR6J", "BR5J":
+		d := bn6Dialect.clone()
+		return &d
+	}
+	return nil
+}
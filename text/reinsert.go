@@ -0,0 +1,72 @@
+package text
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var ErrNoFreeSpace = errors.New("text: no free space in rom")
+
+// FreeByte is the value unused ROM space is filled with.
+const FreeByte = 0xFF
+
+func findFreeSpace(rom []byte, n int) (int, bool) {
+	run := 0
+	for i := 0; i < len(rom); i++ {
+		if rom[i] != FreeByte {
+			run = 0
+			continue
+		}
+
+		// Only start runs on a word boundary.
+		if run == 0 && i%4 != 0 {
+			continue
+		}
+
+		run++
+		if run == n {
+			return i - n + 1, true
+		}
+	}
+	return 0, false
+}
+
+// Reinsert encodes an archive and writes it into rom for the 32-bit pointer at ptrOffset, keeping the pointer's
+// compression flag. The archive replaces the old one in place if it fits in the old one's size, and otherwise is
+// written to the first word-aligned run of free space, leaving the old one untouched for anything else that refers to
+// it. The pointer is updated to match.
+func Reinsert(rom []byte, ptrOffset int64, a *Archive, charmap []rune, d Dialect) error {
+	if ptrOffset < 0 || ptrOffset+4 > int64(len(rom)) {
+		return fmt.Errorf("text: pointer offset 0x%08x out of range", ptrOffset)
+	}
+
+	ptr := binary.LittleEndian.Uint32(rom[ptrOffset:])
+
+	data, err := EncodeArchive(a, charmap, d)
+	if err != nil {
+		return fmt.Errorf("%w while encoding archive", err)
+	}
+
+	if ptr&0x80000000 == 0x80000000 {
+		data = compressLZ77(data)
+	}
+
+	offset := int(ptr & ^uint32(0x88000000))
+	if len(data) > a.Size {
+		var ok bool
+		offset, ok = findFreeSpace(rom, len(data))
+		if !ok {
+			return fmt.Errorf("%w for %d bytes", ErrNoFreeSpace, len(data))
+		}
+	}
+
+	if offset+len(data) > len(rom) {
+		return fmt.Errorf("text: archive at 0x%08x runs past the end of the rom", offset)
+	}
+
+	copy(rom[offset:], data)
+	binary.LittleEndian.PutUint32(rom[ptrOffset:], ptr&0x80000000|0x08000000|uint32(offset))
+
+	return nil
+}
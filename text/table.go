@@ -0,0 +1,161 @@
+package text
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/murkland/bnrom/fonts"
+	"github.com/murkland/bnrom/text/tbl"
+)
+
+var ErrUnsupportedGame = errors.New("text: unsupported game")
+
+// ToTable builds a Thingy table from a charmap and dialect, with extended characters as two-byte entries and commands
+// as control codes.
+func ToTable(charmap []rune, d Dialect) tbl.Table {
+	var t tbl.Table
+
+	for i, c := range charmap {
+		b := []byte{uint8(i)}
+		if i >= int(d.ExtPrefix) {
+			b = []byte{d.ExtPrefix, uint8(i - int(d.ExtPrefix))}
+		}
+		t.Entries = append(t.Entries, tbl.Entry{Kind: tbl.KindNormal, Bytes: b, Value: string(c)})
+	}
+
+	opcodes := make([]int, 0, len(d.Commands))
+	for opcode := range d.Commands {
+		opcodes = append(opcodes, int(opcode))
+	}
+	sort.Ints(opcodes)
+
+	for _, opcode := range opcodes {
+		cmd := d.Commands[uint8(opcode)]
+
+		e := tbl.Entry{Kind: tbl.KindControl, Bytes: []byte{uint8(opcode)}, Value: "[" + cmd.Name + "]", NumParams: cmd.NumParams}
+		switch {
+		case uint8(opcode) == d.End && cmd.NumParams == 0:
+			e.Kind = tbl.KindEnd
+		case uint8(opcode) == d.Newline && cmd.NumParams == 0:
+			e.Kind = tbl.KindNewline
+			e.Value = ""
+		}
+		t.Entries = append(t.Entries, e)
+	}
+
+	return t
+}
+
+// FromTable builds a charmap and dialect from a Thingy table. base provides the extended character prefix, and the
+// commands if the table has no control codes. Charmap slots the table doesn't cover get placeholder codepoints, as in
+// fonts.Placeholder.
+func FromTable(t tbl.Table, base Dialect) ([]rune, Dialect, error) {
+	d := base
+
+	var charmap []rune
+	var commands map[uint8]Command
+	for _, e := range t.Entries {
+		if e.Kind == tbl.KindNormal {
+			var idx int
+			switch {
+			case len(e.Bytes) == 1 && e.Bytes[0] < d.ExtPrefix:
+				idx = int(e.Bytes[0])
+			case len(e.Bytes) == 2 && e.Bytes[0] == d.ExtPrefix:
+				idx = int(d.ExtPrefix) + int(e.Bytes[1])
+			default:
+				return nil, Dialect{}, fmt.Errorf("text: table entry %X is not a character code", e.Bytes)
+			}
+
+			if utf8.RuneCountInString(e.Value) != 1 {
+				return nil, Dialect{}, fmt.Errorf("text: table entry %X must be exactly one character, got %q", e.Bytes, e.Value)
+			}
+
+			for len(charmap) <= idx {
+				charmap = append(charmap, fonts.Placeholder(len(charmap)))
+			}
+			c, _ := utf8.DecodeRuneInString(e.Value)
+			charmap[idx] = c
+			continue
+		}
+
+		if len(e.Bytes) != 1 {
+			return nil, Dialect{}, fmt.Errorf("text: table control code %X must be one byte", e.Bytes)
+		}
+
+		if commands == nil {
+			commands = map[uint8]Command{}
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(e.Value, "["), "]")
+		switch e.Kind {
+		case tbl.KindEnd:
+			d.End = e.Bytes[0]
+			if name == "" {
+				name = "end"
+			}
+		case tbl.KindNewline:
+			d.Newline = e.Bytes[0]
+			name = "newline"
+		}
+
+		commands[e.Bytes[0]] = Command{name, e.NumParams}
+	}
+
+	if commands != nil {
+		d.Commands = commands
+	}
+
+	return charmap, d, nil
+}
+
+// ApplyTable reads a Thingy table and applies it over a built-in charmap and dialect, as in FromTable. The resulting
+// charmap is padded with placeholders to at least the length of the built-in one, so it still covers every glyph.
+func ApplyTable(r io.Reader, charmap []rune, d Dialect) ([]rune, Dialect, error) {
+	t, err := tbl.Read(r)
+	if err != nil {
+		return nil, Dialect{}, err
+	}
+
+	newCharmap, newD, err := FromTable(t, d)
+	if err != nil {
+		return nil, Dialect{}, err
+	}
+
+	for len(newCharmap) < len(charmap) {
+		newCharmap = append(newCharmap, fonts.Placeholder(len(newCharmap)))
+	}
+
+	return newCharmap, newD, nil
+}
+
+// LoadCharmap returns the charmap and dialect of a game. If tblPath is not empty, the Thingy table there is applied over
+// the built-in ones, as in ApplyTable.
+func LoadCharmap(romID string, tblPath string) ([]rune, *Dialect, error) {
+	fontInfo := fonts.FindROMInfo(romID)
+	dialect := FindDialect(romID)
+	if fontInfo == nil || dialect == nil {
+		return nil, nil, ErrUnsupportedGame
+	}
+
+	if tblPath == "" {
+		return fontInfo.Charmap, dialect, nil
+	}
+
+	f, err := os.Open(tblPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	charmap, d, err := ApplyTable(f, fontInfo.Charmap, *dialect)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w while loading %s", err, tblPath)
+	}
+
+	return charmap, &d, nil
+}
@@ -0,0 +1,122 @@
+package tbl
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type Kind int
+
+const (
+	KindNormal Kind = iota
+	KindEnd
+	KindNewline
+	KindControl
+)
+
+type Entry struct {
+	Kind  Kind
+	Bytes []byte
+	Value string
+
+	// NumParams is how many parameter bytes follow a control code.
+	NumParams int
+}
+
+// Table is a Thingy table. Normal entries are written as XX=value, end tokens as /XX=value, line breaks as *XX and
+// control codes as $XX=value,params.
+type Table struct {
+	Entries []Entry
+}
+
+var ErrSyntax = errors.New("tbl: syntax error")
+
+func Write(w io.Writer, t Table) error {
+	for _, e := range t.Entries {
+		code := strings.ToUpper(hex.EncodeToString(e.Bytes))
+
+		var err error
+		switch e.Kind {
+		case KindNormal:
+			_, err = fmt.Fprintf(w, "%s=%s\n", code, e.Value)
+		case KindEnd:
+			_, err = fmt.Fprintf(w, "/%s=%s\n", code, e.Value)
+		case KindNewline:
+			_, err = fmt.Fprintf(w, "*%s\n", code)
+		case KindControl:
+			_, err = fmt.Fprintf(w, "$%s=%s,%d\n", code, e.Value, e.NumParams)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Read(r io.Reader) (Table, error) {
+	var t Table
+
+	s := bufio.NewScanner(r)
+	for lineno := 1; s.Scan(); lineno++ {
+		line := strings.TrimSuffix(s.Text(), "\r")
+		if lineno == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var e Entry
+		switch line[0] {
+		case '/':
+			e.Kind = KindEnd
+			line = line[1:]
+		case '*':
+			e.Kind = KindNewline
+			line = line[1:]
+		case '$':
+			e.Kind = KindControl
+			line = line[1:]
+		}
+
+		code, value, hasValue := strings.Cut(line, "=")
+		if !hasValue && e.Kind != KindNewline && e.Kind != KindEnd {
+			return Table{}, fmt.Errorf("%w: line %d has no value", ErrSyntax, lineno)
+		}
+
+		b, err := hex.DecodeString(code)
+		if err != nil || len(b) == 0 {
+			return Table{}, fmt.Errorf("%w: line %d has bad code %q", ErrSyntax, lineno, code)
+		}
+		e.Bytes = b
+
+		if e.Kind == KindControl {
+			i := strings.LastIndex(value, ",")
+			if i == -1 {
+				return Table{}, fmt.Errorf("%w: line %d has no parameter count", ErrSyntax, lineno)
+			}
+
+			e.NumParams, err = strconv.Atoi(value[i+1:])
+			if err != nil {
+				return Table{}, fmt.Errorf("%w: line %d has bad parameter count", ErrSyntax, lineno)
+			}
+			value = value[:i]
+		}
+		e.Value = value
+
+		t.Entries = append(t.Entries, e)
+	}
+
+	if err := s.Err(); err != nil {
+		return Table{}, err
+	}
+
+	return t, nil
+}
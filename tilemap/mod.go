@@ -0,0 +1,149 @@
+package tilemap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+
+	"github.com/murkland/bnrom/paletted"
+	"github.com/murkland/bnrom/sprites"
+)
+
+// ScreenEntry is one tile of a text mode tilemap.
+type ScreenEntry struct {
+	Tile  int
+	FlipH bool
+	FlipV bool
+
+	// Palbank is ignored for 8bpp tiles.
+	Palbank int
+}
+
+// DecodeScreenEntry unpacks a screen entry: the tile index in bits 0-9, horizontal and vertical flips in bits 10 and
+// 11, and the palbank in bits 12-15.
+func DecodeScreenEntry(v uint16) ScreenEntry {
+	return ScreenEntry{
+		Tile:    int(v & 0x3FF),
+		FlipH:   v&0x400 != 0,
+		FlipV:   v&0x800 != 0,
+		Palbank: int(v >> 12),
+	}
+}
+
+func (se ScreenEntry) Encode() uint16 {
+	v := uint16(se.Tile&0x3FF) | uint16(se.Palbank&0xF)<<12
+	if se.FlipH {
+		v |= 0x400
+	}
+	if se.FlipV {
+		v |= 0x800
+	}
+	return v
+}
+
+type Tilemap struct {
+	WTiles  int
+	HTiles  int
+	Entries []ScreenEntry
+}
+
+func (tm *Tilemap) At(i int, j int) ScreenEntry {
+	return tm.Entries[j*tm.WTiles+i]
+}
+
+// Read reads a tilemap stored row by row.
+func Read(r io.Reader, wTiles int, hTiles int) (*Tilemap, error) {
+	raw := make([]uint16, wTiles*hTiles)
+	if err := binary.Read(r, binary.LittleEndian, raw); err != nil {
+		return nil, fmt.Errorf("%w while reading screen entries", err)
+	}
+
+	tm := &Tilemap{wTiles, hTiles, make([]ScreenEntry, len(raw))}
+	for i, v := range raw {
+		tm.Entries[i] = DecodeScreenEntry(v)
+	}
+	return tm, nil
+}
+
+// Write writes a tilemap row by row, as read by Read.
+func Write(w io.Writer, tm *Tilemap) error {
+	raw := make([]uint16, len(tm.Entries))
+	for i, se := range tm.Entries {
+		raw[i] = se.Encode()
+	}
+	return binary.Write(w, binary.LittleEndian, raw)
+}
+
+const ScreenblockSize = 32
+
+// ReadScreenblocks reads a tilemap stored the way the hardware lays out maps wider or taller than 32 tiles: as
+// consecutive 32x32 screenblocks, left to right then top to bottom. The size must be a multiple of 32 tiles.
+func ReadScreenblocks(r io.Reader, wTiles int, hTiles int) (*Tilemap, error) {
+	if wTiles%ScreenblockSize != 0 || hTiles%ScreenblockSize != 0 {
+		return nil, fmt.Errorf("%dx%d tilemap is not made of whole screenblocks", wTiles, hTiles)
+	}
+
+	tm := &Tilemap{wTiles, hTiles, make([]ScreenEntry, wTiles*hTiles)}
+	for sby := 0; sby < hTiles/ScreenblockSize; sby++ {
+		for sbx := 0; sbx < wTiles/ScreenblockSize; sbx++ {
+			sb, err := Read(r, ScreenblockSize, ScreenblockSize)
+			if err != nil {
+				return nil, fmt.Errorf("%w while reading screenblock (%d, %d)", err, sbx, sby)
+			}
+
+			for j := 0; j < ScreenblockSize; j++ {
+				copy(tm.Entries[(sby*ScreenblockSize+j)*wTiles+sbx*ScreenblockSize:], sb.Entries[j*ScreenblockSize:(j+1)*ScreenblockSize])
+			}
+		}
+	}
+	return tm, nil
+}
+
+// Render draws a tilemap with tiles of the given depth, either 4 or 8 bits per pixel. For 4bpp tiles, pixels are the
+// palbank times 16 plus the tile's color, so palette should hold every palbank in order. Color 0 is left as 0 so the
+// result can be drawn over other layers.
+func Render(tm *Tilemap, tiles []byte, bpp int, palette color.Palette) (*image.Paletted, error) {
+	if bpp != 4 && bpp != 8 {
+		return nil, fmt.Errorf("%w: %d", sprites.ErrUnsupportedBPP, bpp)
+	}
+
+	tileSize := sprites.TileSize(image.Rect(0, 0, 8, 8), bpp)
+
+	img := image.NewPaletted(image.Rect(0, 0, tm.WTiles*8, tm.HTiles*8), palette)
+	for j := 0; j < tm.HTiles; j++ {
+		for i := 0; i < tm.WTiles; i++ {
+			se := tm.At(i, j)
+
+			if (se.Tile+1)*tileSize > len(tiles) {
+				return nil, fmt.Errorf("tile %d at (%d, %d) is past the end of the tiles", se.Tile, i, j)
+			}
+
+			tileImg, err := sprites.ReadTileBPP(bytes.NewReader(tiles[se.Tile*tileSize:(se.Tile+1)*tileSize]), image.Rect(0, 0, 8, 8), bpp)
+			if err != nil {
+				return nil, fmt.Errorf("%w while reading tile %d", err, se.Tile)
+			}
+
+			if se.FlipH {
+				paletted.FlipHorizontal(tileImg)
+			}
+			if se.FlipV {
+				paletted.FlipVertical(tileImg)
+			}
+
+			if bpp == 4 {
+				for k, pix := range tileImg.Pix {
+					if pix != 0 {
+						tileImg.Pix[k] = uint8(se.Palbank)*16 + pix
+					}
+				}
+			}
+
+			paletted.DrawOver(img, image.Rect(i*8, j*8, (i+1)*8, (j+1)*8), tileImg, image.Point{})
+		}
+	}
+
+	return img, nil
+}
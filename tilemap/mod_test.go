@@ -0,0 +1,164 @@
+package tilemap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/murkland/bnrom/sprites"
+)
+
+func TestScreenEntryRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		v  uint16
+		se ScreenEntry
+	}{
+		{0x0000, ScreenEntry{}},
+		{0x03FF, ScreenEntry{Tile: 0x3FF}},
+		{0x0400, ScreenEntry{FlipH: true}},
+		{0x0800, ScreenEntry{FlipV: true}},
+		{0xF000, ScreenEntry{Palbank: 15}},
+		{0x5C2A, ScreenEntry{Tile: 0x02A, FlipV: true, FlipH: true, Palbank: 5}},
+	} {
+		if got := DecodeScreenEntry(tc.v); got != tc.se {
+			t.Errorf("DecodeScreenEntry(0x%04x) = %+v, want %+v", tc.v, got, tc.se)
+		}
+		if got := tc.se.Encode(); got != tc.v {
+			t.Errorf("%+v.Encode() = 0x%04x, want 0x%04x", tc.se, got, tc.v)
+		}
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	tm := &Tilemap{3, 2, []ScreenEntry{
+		{Tile: 1}, {Tile: 2, FlipH: true}, {Tile: 3, Palbank: 4},
+		{Tile: 4, FlipV: true}, {Tile: 5}, {Tile: 6, Palbank: 15},
+	}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, tm); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	got, err := Read(&buf, 3, 2)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	for i, se := range tm.Entries {
+		if got.Entries[i] != se {
+			t.Errorf("entry %d = %+v, want %+v", i, got.Entries[i], se)
+		}
+	}
+}
+
+func TestReadScreenblocks(t *testing.T) {
+	const wTiles, hTiles = 64, 64
+
+	// Each screenblock is stored whole, with its number as the palbank and each entry's position within it as the tile.
+	var buf bytes.Buffer
+	for sb := 0; sb < 4; sb++ {
+		for k := 0; k < ScreenblockSize*ScreenblockSize; k++ {
+			binary.Write(&buf, binary.LittleEndian, ScreenEntry{Tile: k, Palbank: sb}.Encode())
+		}
+	}
+
+	tm, err := ReadScreenblocks(&buf, wTiles, hTiles)
+	if err != nil {
+		t.Fatalf("ReadScreenblocks() error = %v", err)
+	}
+
+	for j := 0; j < hTiles; j++ {
+		for i := 0; i < wTiles; i++ {
+			want := ScreenEntry{
+				Tile:    (j%ScreenblockSize)*ScreenblockSize + i%ScreenblockSize,
+				Palbank: (j/ScreenblockSize)*(wTiles/ScreenblockSize) + i/ScreenblockSize,
+			}
+			if got := tm.At(i, j); got != want {
+				t.Fatalf("At(%d, %d) = %+v, want %+v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestReadScreenblocksPartial(t *testing.T) {
+	if _, err := ReadScreenblocks(bytes.NewReader(nil), 40, 32); err == nil {
+		t.Errorf("ReadScreenblocks() of a 40x32 tilemap succeeded, want error")
+	}
+}
+
+// testTiles returns tile 0, blank, and tile 1, which has color 1 in its top left corner and color 2 to the right of it.
+func testTiles(t *testing.T, bpp int) []byte {
+	blank := image.NewPaletted(image.Rect(0, 0, 8, 8), nil)
+	corner := image.NewPaletted(image.Rect(0, 0, 8, 8), nil)
+	corner.SetColorIndex(0, 0, 1)
+	corner.SetColorIndex(1, 0, 2)
+
+	var buf bytes.Buffer
+	for _, tile := range []*image.Paletted{blank, corner} {
+		if err := sprites.WriteTileBPP(&buf, tile, bpp); err != nil {
+			t.Fatalf("WriteTileBPP() error = %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func testPalette() color.Palette {
+	palette := make(color.Palette, 256)
+	for i := range palette {
+		palette[i] = color.Gray{uint8(i)}
+	}
+	return palette
+}
+
+func TestRender(t *testing.T) {
+	tm := &Tilemap{2, 2, []ScreenEntry{
+		{Tile: 1, Palbank: 0}, {Tile: 1, FlipH: true, Palbank: 2},
+		{Tile: 1, FlipV: true, Palbank: 3}, {Tile: 1, FlipH: true, FlipV: true, Palbank: 15},
+	}}
+
+	for _, tc := range []struct {
+		bpp  int
+		want map[image.Point]uint8
+	}{
+		{4, map[image.Point]uint8{
+			{0, 0}: 1, {1, 0}: 2,
+			{15, 0}: 2*16 + 1, {14, 0}: 2*16 + 2,
+			{0, 15}: 3*16 + 1, {1, 15}: 3*16 + 2,
+			{15, 15}: 15*16 + 1, {14, 15}: 15*16 + 2,
+		}},
+		// Palbanks don't apply to 8bpp tiles.
+		{8, map[image.Point]uint8{
+			{0, 0}: 1, {1, 0}: 2,
+			{15, 0}: 1, {14, 0}: 2,
+			{0, 15}: 1, {1, 15}: 2,
+			{15, 15}: 1, {14, 15}: 2,
+		}},
+	} {
+		img, err := Render(tm, testTiles(t, tc.bpp), tc.bpp, testPalette())
+		if err != nil {
+			t.Fatalf("%dbpp: Render() error = %v", tc.bpp, err)
+		}
+
+		if img.Rect != image.Rect(0, 0, 16, 16) {
+			t.Fatalf("%dbpp: Render() bounds = %v, want %v", tc.bpp, img.Rect, image.Rect(0, 0, 16, 16))
+		}
+
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				if got, want := img.ColorIndexAt(x, y), tc.want[image.Point{x, y}]; got != want {
+					t.Errorf("%dbpp: pixel (%d, %d) = %d, want %d", tc.bpp, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestRenderTileOutOfRange(t *testing.T) {
+	tm := &Tilemap{1, 1, []ScreenEntry{{Tile: 2}}}
+	if _, err := Render(tm, testTiles(t, 4), 4, testPalette()); err == nil {
+		t.Errorf("Render() of a missing tile succeeded, want error")
+	}
+}